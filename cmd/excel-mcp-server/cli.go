@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/negokaz/excel-mcp-server/internal/server"
+	"github.com/negokaz/excel-mcp-server/internal/tools"
+)
+
+// runListTools prints the tools served with the configuration.
+func runListTools(config tools.EnvConfig, args []string) int {
+	flags := flag.NewFlagSet("list-tools", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: excel-mcp-server list-tools [flags]")
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&config.EXCEL_MCP_READ_ONLY, "read-only", config.EXCEL_MCP_READ_ONLY, "List only the tools which do not modify workbooks")
+	jsonOutput := flags.Bool("json", false, "Print the tool definitions including the input and output schemas as JSON")
+	flags.Parse(args)
+
+	s, err := server.New(version, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create the server: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	toolList := s.ListTools()
+	if *jsonOutput {
+		return printJSON(toolList)
+	}
+	for _, tool := range toolList {
+		fmt.Printf("%s\t%s\n", tool.Name, tool.Description)
+	}
+	return 0
+}
+
+// runCall calls a tool with the arguments given as JSON and prints the result.
+// It exits with 1 if the tool returns an error.
+func runCall(config tools.EnvConfig, args []string) int {
+	flags := flag.NewFlagSet("call", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: excel-mcp-server call <tool> [flags]")
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&config.EXCEL_MCP_READ_ONLY, "read-only", config.EXCEL_MCP_READ_ONLY, "Serve only the tools which do not modify workbooks")
+	argsJSON := flags.String("args", "{}", "Arguments of the tool as a JSON object, or \"-\" to read them from stdin")
+	jsonOutput := flags.Bool("json", false, "Print the whole result as JSON")
+	if len(args) == 0 || len(args[0]) == 0 || args[0][0] == '-' {
+		flags.Usage()
+		return 2
+	}
+	toolName := args[0]
+	flags.Parse(args[1:])
+
+	if *argsJSON == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read the arguments: %v\n", err)
+			return 1
+		}
+		*argsJSON = string(data)
+	}
+	var arguments map[string]any
+	if err := json.Unmarshal([]byte(*argsJSON), &arguments); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid arguments: must be a JSON object: %v\n", err)
+		return 2
+	}
+
+	s, err := server.New(version, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create the server: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	// Ctrl+C cancels the call in the same way as a cancellation from a client
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := s.CallTool(ctx, toolName, arguments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to call %s: %v\n", toolName, err)
+		return 1
+	}
+	// Write the changes kept in the workbook cache before the process exits
+	if err := s.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save the workbook: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		if code := printJSON(result); code != 0 {
+			return code
+		}
+	} else {
+		output := os.Stdout
+		if result.IsError {
+			output = os.Stderr
+		}
+		for _, content := range result.Content {
+			switch content := content.(type) {
+			case mcp.TextContent:
+				fmt.Fprintln(output, content.Text)
+			case mcp.ImageContent:
+				fmt.Fprintf(output, "[image: %s, %d bytes in base64]\n", content.MIMEType, len(content.Data))
+			default:
+				fmt.Fprintf(output, "[%T]\n", content)
+			}
+		}
+	}
+	if result.IsError {
+		return 1
+	}
+	return 0
+}
+
+func printJSON(value any) int {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode the output: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
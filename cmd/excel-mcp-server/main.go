@@ -1,10 +1,14 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
+	z "github.com/Oudwins/zog"
+	"github.com/Oudwins/zog/zconst"
 	"github.com/negokaz/excel-mcp-server/internal/server"
+	"github.com/negokaz/excel-mcp-server/internal/tools"
 )
 
 var (
@@ -12,8 +16,46 @@ var (
 )
 
 func main() {
-	s := server.New(version)
-	err := s.Start()
+	config, issues := tools.LoadConfig()
+	if issues != nil {
+		for key, messages := range z.Issues.SanitizeMap(issues) {
+			if key == zconst.ISSUE_KEY_FIRST {
+				continue
+			}
+			for _, message := range messages {
+				fmt.Fprintf(os.Stderr, "Invalid configuration: %s: %s\n", key, message)
+			}
+		}
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "list-tools":
+			os.Exit(runListTools(config, os.Args[2:]))
+		case "call":
+			os.Exit(runCall(config, os.Args[2:]))
+		}
+	}
+
+	transport := flag.String("transport", config.EXCEL_MCP_TRANSPORT, "Transport to serve: stdio, streamable-http or sse")
+	addr := flag.String("addr", config.EXCEL_MCP_HTTP_ADDR, "Address to listen on for HTTP transports")
+	basePath := flag.String("base-path", config.EXCEL_MCP_HTTP_BASE_PATH, "Base URL path for HTTP transports")
+	authToken := flag.String("auth-token", config.EXCEL_MCP_HTTP_AUTH_TOKEN, "Bearer token required by HTTP transports (optional)")
+	flag.BoolVar(&config.EXCEL_MCP_READ_ONLY, "read-only", config.EXCEL_MCP_READ_ONLY, "Serve only the tools which do not modify workbooks")
+	flag.Parse()
+
+	s, err := server.New(version, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create the server: %v\n", err)
+		os.Exit(1)
+	}
+	err = s.Start(server.TransportOptions{
+		Transport: *transport,
+		Addr:      *addr,
+		BasePath:  *basePath,
+		AuthToken: *authToken,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start the server: %v\n", err)
 		os.Exit(1)
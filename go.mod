@@ -1,22 +1,22 @@
 module github.com/negokaz/excel-mcp-server
 
-go 1.23.0
-
-toolchain go1.24.0
+go 1.25.5
 
 require (
 	github.com/Oudwins/zog v0.21.4
 	github.com/go-ole/go-ole v1.3.0
 	github.com/goccy/go-yaml v1.18.0
-	github.com/mark3labs/mcp-go v0.34.0
+	github.com/mark3labs/mcp-go v0.58.0
 	github.com/skanehira/clipboard-image v1.0.0
 	github.com/xuri/excelize/v2 v2.9.2-0.20250717000717-dd07139785fe
 )
 
 require (
+	github.com/google/jsonschema-go v0.4.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.4 // indirect
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2 // indirect
 	github.com/spf13/cast v1.9.2 // indirect
 	github.com/tiendc/go-deepcopy v1.6.1 // indirect
 	github.com/xuri/efp v0.0.1 // indirect
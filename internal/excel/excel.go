@@ -1,11 +1,15 @@
 package excel
 
 import (
+	"context"
 	"os"
 
 	"github.com/xuri/excelize/v2"
 )
 
+// ProgressFunc receives the number of processed rows and the total number of rows of a long-running operation.
+type ProgressFunc func(current int, total int)
+
 type Excel interface {
 	// GetBackendName returns the backend used to manipulate the Excel file.
 	GetBackendName() string
@@ -46,6 +50,8 @@ type Worksheet interface {
 	GetValue(cell string) (string, error)
 	// GetFormula gets the formula from the specified cell.
 	GetFormula(cell string) (string, error)
+	// GetCellInfo gets the raw value, the type, the number format and the formatted text of the specified cell.
+	GetCellInfo(cell string) (*CellInfo, error)
 	// GetDimension gets the dimension of the worksheet.
 	GetDimension() (string, error)
 	// GetPagingStrategy returns the paging strategy for the worksheet.
@@ -63,6 +69,8 @@ type Worksheet interface {
 	MergeCells(mergeRange string) error
 	// UnmergeCells unmerges cells in the specified range.
 	UnmergeCells(mergeRange string) error
+	// GetMergedCells returns the merged ranges which overlap the specified range.
+	GetMergedCells(cellRange string) ([]string, error)
 	// SetColumnWidth sets the width of columns in the specified range.
 	SetColumnWidth(startCol, endCol string, width float64) error
 	// SetRowHeight sets the height of the specified row.
@@ -82,7 +90,8 @@ type Worksheet interface {
 	// AddDataValidation adds a data validation rule to the specified range.
 	AddDataValidation(validationRange string, validationType string, formula1 string, formula2 string, allowBlank bool) error
 	// FindReplace finds and replaces values in the worksheet. Returns the number of replacements made.
-	FindReplace(searchRange string, find string, replace string, matchCase bool, matchEntireCell bool) (int, error)
+	// It stops with ctx.Err() when ctx is cancelled. progress may be nil.
+	FindReplace(ctx context.Context, searchRange string, find string, replace string, matchCase bool, matchEntireCell bool, progress ProgressFunc) (int, error)
 	// AddComment adds a comment to the specified cell.
 	AddComment(cell string, author string, text string) error
 	// GetComments returns all comments in the worksheet.
@@ -115,12 +124,28 @@ type DefinedName struct {
 	Scope    string
 }
 
+// CellInfo is the value of a cell along with its type.
+type CellInfo struct {
+	// Value is the raw value: a number without the number format, e.g. the serial number of a date, "TRUE" or "FALSE" for a boolean
+	Value string
+	Type  CellValueType
+	// ResultType is the type of the calculated value of a formula
+	ResultType CellValueType
+	Formula    string
+	// NumFmt is the number format code, empty for General
+	NumFmt string
+	// Text is the value formatted by the number format, the same as GetValue
+	Text string
+}
+
 type CellStyle struct {
-	Border        []Border   `yaml:"border,omitempty"`
-	Font          *FontStyle `yaml:"font,omitempty"`
-	Fill          *FillStyle `yaml:"fill,omitempty"`
-	NumFmt        *string    `yaml:"numFmt,omitempty"`
-	DecimalPlaces *int       `yaml:"decimalPlaces,omitempty"`
+	Border        []Border         `yaml:"border,omitempty"`
+	Font          *FontStyle       `yaml:"font,omitempty"`
+	Fill          *FillStyle       `yaml:"fill,omitempty"`
+	Alignment     *AlignmentStyle  `yaml:"alignment,omitempty"`
+	Protection    *ProtectionStyle `yaml:"protection,omitempty"`
+	NumFmt        *string          `yaml:"numFmt,omitempty"`
+	DecimalPlaces *int             `yaml:"decimalPlaces,omitempty"`
 }
 
 type Border struct {
@@ -146,27 +171,42 @@ type FillStyle struct {
 	Shading *FillShading `yaml:"shading,omitempty"`
 }
 
+type AlignmentStyle struct {
+	Horizontal *HorizontalAlignment `yaml:"horizontal,omitempty"`
+	Vertical   *VerticalAlignment   `yaml:"vertical,omitempty"`
+	WrapText   *bool                `yaml:"wrapText,omitempty"`
+	Indent     *int                 `yaml:"indent,omitempty"`
+	// TextRotation is the angle of the text from -90 to 90 degrees, or TextRotationVertical for stacked text
+	TextRotation *int  `yaml:"textRotation,omitempty"`
+	ShrinkToFit  *bool `yaml:"shrinkToFit,omitempty"`
+}
+
+// TextRotationVertical is the text rotation of the text stacked vertically
+const TextRotationVertical = 255
+
+// ProtectionStyle is the protection of the cell, which takes effect when the sheet is protected.
+// A cell is locked and not hidden by default.
+type ProtectionStyle struct {
+	Locked *bool `yaml:"locked,omitempty"`
+	Hidden *bool `yaml:"hidden,omitempty"`
+}
+
 // OpenFile opens an Excel file and returns an Excel interface.
 // It first tries to open the file using OLE automation, and if that fails,
 // it tries to using the excelize library.
 // If the file does not exist, it creates a new file using excelize.
+// Workbooks opened with excelize are reused while the workbook cache is enabled.
 func OpenFile(absoluteFilePath string) (Excel, func(), error) {
 	ole, releaseFn, err := NewExcelOle(absoluteFilePath)
 	if err == nil {
 		return ole, releaseFn, nil
 	}
 	// If OLE fails, try Excelize
-	workbook, err := excelize.OpenFile(absoluteFilePath)
+	if cache := getWorkbookCache(); cache != nil {
+		return cache.open(absoluteFilePath)
+	}
+	workbook, err := openExcelizeFile(absoluteFilePath)
 	if err != nil {
-		// If the file does not exist, create a new one
-		if os.IsNotExist(err) {
-			workbook = excelize.NewFile()
-			workbook.Path = absoluteFilePath
-			e := NewExcelizeExcel(workbook)
-			return e, func() {
-				workbook.Close()
-			}, nil
-		}
 		return nil, func() {}, err
 	}
 	e := NewExcelizeExcel(workbook)
@@ -175,6 +215,34 @@ func OpenFile(absoluteFilePath string) (Excel, func(), error) {
 	}, nil
 }
 
+// openExcelizeFile opens an Excel file with excelize.
+// If the file does not exist, it creates a new workbook which is saved to the path.
+func openExcelizeFile(absoluteFilePath string) (*excelize.File, error) {
+	workbook, err := excelize.OpenFile(absoluteFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			workbook = excelize.NewFile()
+			workbook.Path = absoluteFilePath
+			return workbook, nil
+		}
+		return nil, err
+	}
+	return workbook, nil
+}
+
+// CellValueType represents the type of a cell value
+type CellValueType string
+
+const (
+	CellValueTypeEmpty   CellValueType = "empty"
+	CellValueTypeNumber  CellValueType = "number"
+	CellValueTypeDate    CellValueType = "date"
+	CellValueTypeBool    CellValueType = "bool"
+	CellValueTypeString  CellValueType = "string"
+	CellValueTypeError   CellValueType = "error"
+	CellValueTypeFormula CellValueType = "formula"
+)
+
 // BorderType represents border direction
 type BorderType string
 
@@ -408,3 +476,67 @@ func FillShadingValues() []FillShading {
 		FillShadingFromCorner,
 	}
 }
+
+// HorizontalAlignment represents horizontal alignment options for cell styles
+type HorizontalAlignment string
+
+const (
+	HorizontalAlignmentGeneral          HorizontalAlignment = "general"
+	HorizontalAlignmentLeft             HorizontalAlignment = "left"
+	HorizontalAlignmentCenter           HorizontalAlignment = "center"
+	HorizontalAlignmentRight            HorizontalAlignment = "right"
+	HorizontalAlignmentFill             HorizontalAlignment = "fill"
+	HorizontalAlignmentJustify          HorizontalAlignment = "justify"
+	HorizontalAlignmentCenterContinuous HorizontalAlignment = "centerContinuous"
+	HorizontalAlignmentDistributed      HorizontalAlignment = "distributed"
+)
+
+func (h HorizontalAlignment) String() string {
+	return string(h)
+}
+
+func (h HorizontalAlignment) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func HorizontalAlignmentValues() []HorizontalAlignment {
+	return []HorizontalAlignment{
+		HorizontalAlignmentGeneral,
+		HorizontalAlignmentLeft,
+		HorizontalAlignmentCenter,
+		HorizontalAlignmentRight,
+		HorizontalAlignmentFill,
+		HorizontalAlignmentJustify,
+		HorizontalAlignmentCenterContinuous,
+		HorizontalAlignmentDistributed,
+	}
+}
+
+// VerticalAlignment represents vertical alignment options for cell styles
+type VerticalAlignment string
+
+const (
+	VerticalAlignmentTop         VerticalAlignment = "top"
+	VerticalAlignmentCenter      VerticalAlignment = "center"
+	VerticalAlignmentBottom      VerticalAlignment = "bottom"
+	VerticalAlignmentJustify     VerticalAlignment = "justify"
+	VerticalAlignmentDistributed VerticalAlignment = "distributed"
+)
+
+func (v VerticalAlignment) String() string {
+	return string(v)
+}
+
+func (v VerticalAlignment) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+func VerticalAlignmentValues() []VerticalAlignment {
+	return []VerticalAlignment{
+		VerticalAlignmentTop,
+		VerticalAlignmentCenter,
+		VerticalAlignmentBottom,
+		VerticalAlignmentJustify,
+		VerticalAlignmentDistributed,
+	}
+}
@@ -1,9 +1,11 @@
 package excel
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/xuri/excelize/v2"
@@ -11,6 +13,10 @@ import (
 
 type ExcelizeExcel struct {
 	file *excelize.File
+	// modified is set by every mutating operation and cleared by Save
+	modified bool
+	// saveLater replaces writing the workbook to disk while it is in the workbook cache
+	saveLater func() error
 }
 
 func NewExcelizeExcel(file *excelize.File) Excel {
@@ -29,10 +35,11 @@ func (e *ExcelizeExcel) FindSheet(sheetName string) (Worksheet, error) {
 	if index < 0 {
 		return nil, fmt.Errorf("sheet not found: %s", sheetName)
 	}
-	return &ExcelizeWorksheet{file: e.file, sheetName: sheetName}, nil
+	return &ExcelizeWorksheet{file: e.file, sheetName: sheetName, workbook: e}, nil
 }
 
 func (e *ExcelizeExcel) CreateNewSheet(sheetName string) error {
+	e.modified = true
 	_, err := e.file.NewSheet(sheetName)
 	if err != nil {
 		return fmt.Errorf("failed to create new sheet: %w", err)
@@ -41,6 +48,7 @@ func (e *ExcelizeExcel) CreateNewSheet(sheetName string) error {
 }
 
 func (e *ExcelizeExcel) CopySheet(srcSheetName string, destSheetName string) error {
+	e.modified = true
 	srcIndex, err := e.file.GetSheetIndex(srcSheetName)
 	if err != nil {
 		return fmt.Errorf("source sheet not found: %s: %w", srcSheetName, err)
@@ -66,34 +74,57 @@ func (e *ExcelizeExcel) GetSheets() ([]Worksheet, error) {
 	sheetList := e.file.GetSheetList()
 	worksheets := make([]Worksheet, len(sheetList))
 	for i, sheetName := range sheetList {
-		worksheets[i] = &ExcelizeWorksheet{file: e.file, sheetName: sheetName}
+		worksheets[i] = &ExcelizeWorksheet{file: e.file, sheetName: sheetName, workbook: e}
 	}
 	return worksheets, nil
 }
 
-// SaveExcelize saves the Excel file to the specified path.
+// Save saves the Excel file to its path atomically.
+// The workbook is written to a temporary file in the same directory, which is
+// synced and then renamed over the original, so that a crash during the write
+// never leaves a truncated workbook behind.
+// While the workbook is in the workbook cache, it is written when the cache is flushed instead.
 // Excelize's Save method restricts the file path length to 207 characters,
 // but since this limitation has been relaxed in some environments,
 // we ignore this restriction.
 // https://github.com/qax-os/excelize/blob/v2.9.0/file.go#L71-L73
 func (w *ExcelizeExcel) Save() error {
-	file, err := os.OpenFile(filepath.Clean(w.file.Path), os.O_WRONLY|os.O_TRUNC|os.O_CREATE, os.ModePerm)
+	var err error
+	if w.saveLater != nil {
+		err = w.saveLater()
+	} else {
+		err = WriteFileAtomically(filepath.Clean(w.file.Path), func(writer io.Writer) error {
+			return w.file.Write(writer)
+		})
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	return w.file.Write(file)
+	w.modified = false
+	return nil
+}
+
+// IsModified reports whether the workbook has changes which are not saved yet.
+func (w *ExcelizeExcel) IsModified() bool {
+	return w.modified
 }
 
 type ExcelizeWorksheet struct {
 	file      *excelize.File
 	sheetName string
+	workbook  *ExcelizeExcel
 }
 
 func (w *ExcelizeWorksheet) Release() {
 	// No resources to release in excelize
 }
 
+func (w *ExcelizeWorksheet) markModified() {
+	if w.workbook != nil {
+		w.workbook.modified = true
+	}
+}
+
 func (w *ExcelizeWorksheet) Name() (string, error) {
 	return w.sheetName, nil
 }
@@ -129,6 +160,7 @@ func (w *ExcelizeWorksheet) GetPivotTables() ([]PivotTable, error) {
 }
 
 func (w *ExcelizeWorksheet) SetValue(cell string, value any) error {
+	w.markModified()
 	if err := w.file.SetCellValue(w.sheetName, cell, value); err != nil {
 		return err
 	}
@@ -139,6 +171,7 @@ func (w *ExcelizeWorksheet) SetValue(cell string, value any) error {
 }
 
 func (w *ExcelizeWorksheet) SetFormula(cell string, formula string) error {
+	w.markModified()
 	if err := w.file.SetCellFormula(w.sheetName, cell, formula); err != nil {
 		return err
 	}
@@ -166,6 +199,85 @@ func (w *ExcelizeWorksheet) GetValue(cell string) (string, error) {
 	return value, nil
 }
 
+func (w *ExcelizeWorksheet) GetCellInfo(cell string) (*CellInfo, error) {
+	text, err := w.GetValue(cell)
+	if err != nil {
+		return nil, err
+	}
+	value, err := w.file.GetCellValue(w.sheetName, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cell value: %w", err)
+	}
+	cellType, err := w.file.GetCellType(w.sheetName, cell)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cell type: %w", err)
+	}
+	formula, err := w.file.GetCellFormula(w.sheetName, cell)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get formula: %w", err)
+	}
+	styleID, err := w.file.GetCellStyle(w.sheetName, cell)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cell style: %w", err)
+	}
+	style, err := w.file.GetStyle(styleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get style details: %w", err)
+	}
+
+	info := &CellInfo{Value: value, Text: text}
+	if style.CustomNumFmt != nil {
+		info.NumFmt = *style.CustomNumFmt
+	} else {
+		info.NumFmt = builtInNumFmts[style.NumFmt]
+	}
+	if formula != "" && value == "" {
+		// The calculated value is not cached in the file, so its type is inferred from the value
+		info.Value = text
+		cellType = excelize.CellTypeUnset
+	}
+
+	var valueType CellValueType
+	switch cellType {
+	case excelize.CellTypeBool:
+		valueType = CellValueTypeBool
+		if info.Value == "1" {
+			info.Value = "TRUE"
+		} else if info.Value == "0" {
+			info.Value = "FALSE"
+		}
+	case excelize.CellTypeDate:
+		valueType = CellValueTypeDate
+	case excelize.CellTypeError:
+		valueType = CellValueTypeError
+	case excelize.CellTypeSharedString, excelize.CellTypeInlineString, excelize.CellTypeFormula:
+		// The result of a formula is a string if its type is "str"
+		valueType = CellValueTypeString
+	default:
+		if _, err := strconv.ParseFloat(info.Value, 64); err != nil {
+			valueType = CellValueTypeString
+		} else if (style.CustomNumFmt == nil && builtInDateNumFmtIDs[style.NumFmt]) || isDateNumFmt(info.NumFmt) {
+			valueType = CellValueTypeDate
+		} else {
+			valueType = CellValueTypeNumber
+		}
+	}
+	switch {
+	case formula != "":
+		if !strings.HasPrefix(formula, "=") {
+			formula = "=" + formula
+		}
+		info.Type = CellValueTypeFormula
+		info.ResultType = valueType
+		info.Formula = formula
+	case info.Value == "":
+		info.Type = CellValueTypeEmpty
+	default:
+		info.Type = valueType
+	}
+	return info, nil
+}
+
 func (w *ExcelizeWorksheet) GetFormula(cell string) (string, error) {
 	formula, err := w.file.GetCellFormula(w.sheetName, cell)
 	if err != nil {
@@ -194,6 +306,7 @@ func (w *ExcelizeWorksheet) CapturePicture(captureRange string) (string, error)
 }
 
 func (w *ExcelizeWorksheet) AddTable(tableRange, tableName string) error {
+	w.markModified()
 	enable := true
 	if err := w.file.AddTable(w.sheetName, &excelize.Table{
 		Range:             tableRange,
@@ -225,6 +338,7 @@ func (w *ExcelizeWorksheet) GetCellStyle(cell string) (*CellStyle, error) {
 }
 
 func (w *ExcelizeWorksheet) SetCellStyle(cell string, style *CellStyle) error {
+	w.markModified()
 	excelizeStyle := convertCellStyleToExcelizeStyle(style)
 
 	styleID, err := w.file.NewStyle(excelizeStyle)
@@ -305,6 +419,43 @@ func convertCellStyleToExcelizeStyle(style *CellStyle) *excelize.Style {
 		result.Fill = fill
 	}
 
+	// Alignment
+	if style.Alignment != nil {
+		alignment := &excelize.Alignment{}
+		if style.Alignment.Horizontal != nil {
+			alignment.Horizontal = style.Alignment.Horizontal.String()
+		}
+		if style.Alignment.Vertical != nil {
+			alignment.Vertical = style.Alignment.Vertical.String()
+		}
+		if style.Alignment.WrapText != nil {
+			alignment.WrapText = *style.Alignment.WrapText
+		}
+		if style.Alignment.Indent != nil {
+			alignment.Indent = *style.Alignment.Indent
+		}
+		if style.Alignment.TextRotation != nil {
+			alignment.TextRotation = textRotationToExcelize(*style.Alignment.TextRotation)
+		}
+		if style.Alignment.ShrinkToFit != nil {
+			alignment.ShrinkToFit = *style.Alignment.ShrinkToFit
+		}
+		result.Alignment = alignment
+	}
+
+	// Protection
+	if style.Protection != nil {
+		// A cell is locked unless it is specified
+		protection := &excelize.Protection{Locked: true}
+		if style.Protection.Locked != nil {
+			protection.Locked = *style.Protection.Locked
+		}
+		if style.Protection.Hidden != nil {
+			protection.Hidden = *style.Protection.Hidden
+		}
+		result.Protection = protection
+	}
+
 	// NumFmt
 	if style.NumFmt != nil && *style.NumFmt != "" {
 		result.CustomNumFmt = style.NumFmt
@@ -403,6 +554,46 @@ func convertExcelizeStyleToCellStyle(style *excelize.Style) *CellStyle {
 		}
 	}
 
+	// Alignment, only the values which differ from the default like the OLE backend
+	if style.Alignment != nil {
+		alignment := &AlignmentStyle{}
+		if horizontal := HorizontalAlignment(style.Alignment.Horizontal); horizontal != "" && horizontal != HorizontalAlignmentGeneral {
+			alignment.Horizontal = &horizontal
+		}
+		if vertical := VerticalAlignment(style.Alignment.Vertical); vertical != "" && vertical != VerticalAlignmentBottom {
+			alignment.Vertical = &vertical
+		}
+		if style.Alignment.WrapText {
+			alignment.WrapText = &style.Alignment.WrapText
+		}
+		if style.Alignment.Indent != 0 {
+			alignment.Indent = &style.Alignment.Indent
+		}
+		if style.Alignment.TextRotation != 0 {
+			textRotation := excelizeTextRotationToDegrees(style.Alignment.TextRotation)
+			alignment.TextRotation = &textRotation
+		}
+		if style.Alignment.ShrinkToFit {
+			alignment.ShrinkToFit = &style.Alignment.ShrinkToFit
+		}
+		if alignment.Horizontal != nil || alignment.Vertical != nil || alignment.WrapText != nil || alignment.Indent != nil || alignment.TextRotation != nil || alignment.ShrinkToFit != nil {
+			result.Alignment = alignment
+		}
+	}
+
+	// Protection
+	if style.Protection != nil && (!style.Protection.Locked || style.Protection.Hidden) {
+		// Only the flags which differ from the default, locked and not hidden, are returned
+		protection := &ProtectionStyle{}
+		if !style.Protection.Locked {
+			protection.Locked = &style.Protection.Locked
+		}
+		if style.Protection.Hidden {
+			protection.Hidden = &style.Protection.Hidden
+		}
+		result.Protection = protection
+	}
+
 	// NumFmt
 	if style.CustomNumFmt != nil && *style.CustomNumFmt != "" {
 		result.NumFmt = style.CustomNumFmt
@@ -416,6 +607,23 @@ func convertExcelizeStyleToCellStyle(style *excelize.Style) *CellStyle {
 	return result
 }
 
+// textRotationToExcelize converts the angle from -90 to 90 degrees into the text rotation of excelize,
+// which represents the angles below zero as 91 to 180.
+func textRotationToExcelize(degrees int) int {
+	if degrees < 0 {
+		return 90 - degrees
+	}
+	return degrees
+}
+
+// excelizeTextRotationToDegrees converts the text rotation of excelize into the angle from -90 to 90 degrees.
+func excelizeTextRotationToDegrees(textRotation int) int {
+	if textRotation > 90 && textRotation <= 180 {
+		return 90 - textRotation
+	}
+	return textRotation
+}
+
 func intToBorderStyleName(style int) BorderStyle {
 	styles := map[int]BorderStyle{
 		0:  BorderStyleNone,
@@ -583,6 +791,7 @@ func (w *ExcelizeWorksheet) updateDimension(updatedCell string) error {
 }
 
 func (e *ExcelizeExcel) DeleteSheet(sheetName string) error {
+	e.modified = true
 	if err := e.file.DeleteSheet(sheetName); err != nil {
 		return fmt.Errorf("failed to delete sheet: %w", err)
 	}
@@ -590,6 +799,7 @@ func (e *ExcelizeExcel) DeleteSheet(sheetName string) error {
 }
 
 func (e *ExcelizeExcel) RenameSheet(oldName, newName string) error {
+	e.modified = true
 	if err := e.file.SetSheetName(oldName, newName); err != nil {
 		return fmt.Errorf("failed to rename sheet: %w", err)
 	}
@@ -597,6 +807,7 @@ func (e *ExcelizeExcel) RenameSheet(oldName, newName string) error {
 }
 
 func (w *ExcelizeWorksheet) MergeCells(mergeRange string) error {
+	w.markModified()
 	startCol, startRow, endCol, endRow, err := ParseRange(mergeRange)
 	if err != nil {
 		return err
@@ -613,6 +824,7 @@ func (w *ExcelizeWorksheet) MergeCells(mergeRange string) error {
 }
 
 func (w *ExcelizeWorksheet) UnmergeCells(mergeRange string) error {
+	w.markModified()
 	startCol, startRow, endCol, endRow, err := ParseRange(mergeRange)
 	if err != nil {
 		return err
@@ -628,19 +840,46 @@ func (w *ExcelizeWorksheet) UnmergeCells(mergeRange string) error {
 	return w.file.UnmergeCell(w.sheetName, topLeft, bottomRight)
 }
 
+func (w *ExcelizeWorksheet) GetMergedCells(cellRange string) ([]string, error) {
+	startCol, startRow, endCol, endRow, err := ParseRange(cellRange)
+	if err != nil {
+		return nil, err
+	}
+	mergeCells, err := w.file.GetMergeCells(w.sheetName)
+	if err != nil {
+		return nil, err
+	}
+	merged := []string{}
+	for _, mergeCell := range mergeCells {
+		mergeRange := fmt.Sprintf("%s:%s", mergeCell.GetStartAxis(), mergeCell.GetEndAxis())
+		mergeStartCol, mergeStartRow, mergeEndCol, mergeEndRow, err := ParseRange(mergeRange)
+		if err != nil {
+			return nil, err
+		}
+		if mergeStartCol <= endCol && mergeEndCol >= startCol && mergeStartRow <= endRow && mergeEndRow >= startRow {
+			merged = append(merged, mergeRange)
+		}
+	}
+	return merged, nil
+}
+
 func (w *ExcelizeWorksheet) SetColumnWidth(startCol, endCol string, width float64) error {
+	w.markModified()
 	return w.file.SetColWidth(w.sheetName, startCol, endCol, width)
 }
 
 func (w *ExcelizeWorksheet) SetRowHeight(row int, height float64) error {
+	w.markModified()
 	return w.file.SetRowHeight(w.sheetName, row, height)
 }
 
 func (w *ExcelizeWorksheet) InsertRows(row int, count int) error {
+	w.markModified()
 	return w.file.InsertRows(w.sheetName, row, count)
 }
 
 func (w *ExcelizeWorksheet) DeleteRows(row int, count int) error {
+	w.markModified()
 	for i := 0; i < count; i++ {
 		if err := w.file.RemoveRow(w.sheetName, row); err != nil {
 			return fmt.Errorf("failed to delete row %d: %w", row, err)
@@ -650,10 +889,12 @@ func (w *ExcelizeWorksheet) DeleteRows(row int, count int) error {
 }
 
 func (w *ExcelizeWorksheet) InsertColumns(column string, count int) error {
+	w.markModified()
 	return w.file.InsertCols(w.sheetName, column, count)
 }
 
 func (w *ExcelizeWorksheet) DeleteColumns(column string, count int) error {
+	w.markModified()
 	for i := 0; i < count; i++ {
 		if err := w.file.RemoveCol(w.sheetName, column); err != nil {
 			return fmt.Errorf("failed to delete column %s (iteration %d): %w", column, i+1, err)
@@ -663,6 +904,7 @@ func (w *ExcelizeWorksheet) DeleteColumns(column string, count int) error {
 }
 
 func (w *ExcelizeWorksheet) AddChart(position string, chartType string, dataRange string, title string) error {
+	w.markModified()
 	ct := mapExcelizeChartType(chartType)
 	chart := &excelize.Chart{
 		Type: ct,
@@ -697,6 +939,7 @@ func mapExcelizeChartType(chartType string) excelize.ChartType {
 }
 
 func (w *ExcelizeWorksheet) FreezePanes(cell string) error {
+	w.markModified()
 	col, row, err := excelize.CellNameToCoordinates(cell)
 	if err != nil {
 		return err
@@ -712,6 +955,7 @@ func (w *ExcelizeWorksheet) FreezePanes(cell string) error {
 }
 
 func (w *ExcelizeWorksheet) AddDataValidation(validationRange string, validationType string, formula1 string, formula2 string, allowBlank bool) error {
+	w.markModified()
 	dv := excelize.NewDataValidation(allowBlank)
 	dv.Sqref = validationRange
 	switch validationType {
@@ -727,7 +971,8 @@ func (w *ExcelizeWorksheet) AddDataValidation(validationRange string, validation
 	return w.file.AddDataValidation(w.sheetName, dv)
 }
 
-func (w *ExcelizeWorksheet) FindReplace(searchRange string, find string, replace string, matchCase bool, matchEntireCell bool) (int, error) {
+func (w *ExcelizeWorksheet) FindReplace(ctx context.Context, searchRange string, find string, replace string, matchCase bool, matchEntireCell bool, progress ProgressFunc) (int, error) {
+	w.markModified()
 	rangeStr := searchRange
 	if rangeStr == "" {
 		dim, err := w.GetDimension()
@@ -742,6 +987,12 @@ func (w *ExcelizeWorksheet) FindReplace(searchRange string, find string, replace
 	}
 	count := 0
 	for row := startRow; row <= endRow; row++ {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		if progress != nil {
+			progress(row-startRow, endRow-startRow+1)
+		}
 		for col := startCol; col <= endCol; col++ {
 			cell, err := excelize.CoordinatesToCellName(col, row)
 			if err != nil {
@@ -796,10 +1047,14 @@ func (w *ExcelizeWorksheet) FindReplace(searchRange string, find string, replace
 			}
 		}
 	}
+	if progress != nil {
+		progress(endRow-startRow+1, endRow-startRow+1)
+	}
 	return count, nil
 }
 
 func (w *ExcelizeWorksheet) AddComment(cell string, author string, text string) error {
+	w.markModified()
 	return w.file.AddComment(w.sheetName, excelize.Comment{
 		Cell:   cell,
 		Author: author,
@@ -820,6 +1075,7 @@ func (w *ExcelizeWorksheet) GetComments() ([]Comment, error) {
 }
 
 func (w *ExcelizeWorksheet) AddHyperlink(cell string, url string, display string) error {
+	w.markModified()
 	linkType := "External"
 	if strings.Contains(url, "!") && !strings.HasPrefix(url, "http") {
 		linkType = "Location"
@@ -838,6 +1094,7 @@ func (w *ExcelizeWorksheet) AddHyperlink(cell string, url string, display string
 }
 
 func (w *ExcelizeWorksheet) SetConditionalFormat(formatRange string, ruleType string, criteria string, value string, value2 string, fontColor string, bgColor string) error {
+	w.markModified()
 	opt := excelize.ConditionalFormatOptions{}
 
 	switch ruleType {
@@ -894,6 +1151,7 @@ func (w *ExcelizeWorksheet) SetConditionalFormat(formatRange string, ruleType st
 }
 
 func (e *ExcelizeExcel) SetDefinedName(name string, refersTo string, scope string) error {
+	e.modified = true
 	return e.file.SetDefinedName(&excelize.DefinedName{
 		Name:     name,
 		RefersTo: refersTo,
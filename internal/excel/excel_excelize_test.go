@@ -0,0 +1,121 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExcelizeWorksheetGetCellInfo(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	numberStyle, err := file.NewStyle(&excelize.Style{NumFmt: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.SetCellValue("Sheet1", "A1", 1234.5)
+	file.SetCellStyle("Sheet1", "A1", "A1", numberStyle)
+	file.SetCellValue("Sheet1", "A2", 45296)
+	file.SetCellStyle("Sheet1", "A2", "A2", dateStyle)
+	file.SetCellValue("Sheet1", "A3", "45296")
+	file.SetCellValue("Sheet1", "A4", true)
+	file.SetCellFormula("Sheet1", "A5", "A1*2")
+	// Built-in date formats of the East Asian locales have no format code common to all locales
+	for i, numFmt := range []int{27, 31, 57, 58} {
+		style, err := file.NewStyle(&excelize.Style{NumFmt: numFmt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cell := fmt.Sprintf("C%d", i+1)
+		file.SetCellValue("Sheet1", cell, 45296)
+		file.SetCellStyle("Sheet1", cell, cell, style)
+	}
+
+	workbook := NewExcelizeExcel(file)
+	worksheet, err := workbook.FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worksheet.Release()
+
+	tests := []struct {
+		cell string
+		want CellInfo
+	}{
+		{"A1", CellInfo{Value: "1234.5", Type: CellValueTypeNumber, NumFmt: "#,##0.00", Text: "1,234.50"}},
+		{"A2", CellInfo{Value: "45296", Type: CellValueTypeDate, NumFmt: "yyyy-mm-dd", Text: "2024-01-05"}},
+		{"A3", CellInfo{Value: "45296", Type: CellValueTypeString, Text: "45296"}},
+		{"A4", CellInfo{Value: "TRUE", Type: CellValueTypeBool, Text: "TRUE"}},
+		{"A5", CellInfo{Value: "2469", Type: CellValueTypeFormula, ResultType: CellValueTypeNumber, Formula: "=A1*2", Text: "2469"}},
+		{"B1", CellInfo{Type: CellValueTypeEmpty}},
+		{"C1", CellInfo{Value: "45296", Type: CellValueTypeDate, Text: "45296"}},
+		{"C2", CellInfo{Value: "45296", Type: CellValueTypeDate, Text: "45296"}},
+		{"C3", CellInfo{Value: "45296", Type: CellValueTypeDate, Text: "45296"}},
+		{"C4", CellInfo{Value: "45296", Type: CellValueTypeDate, Text: "45296"}},
+	}
+	for _, tt := range tests {
+		got, err := worksheet.GetCellInfo(tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != tt.want {
+			t.Errorf("GetCellInfo(%q) = %+v, want %+v", tt.cell, *got, tt.want)
+		}
+	}
+}
+
+func TestExcelizeWorksheetCellStyleAlignmentAndProtection(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	workbook := NewExcelizeExcel(file)
+	worksheet, err := workbook.FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worksheet.Release()
+
+	horizontal, vertical := HorizontalAlignmentCenter, VerticalAlignmentTop
+	wrapText, shrinkToFit, unlocked, hidden := true, true, false, true
+	indent, downward, verticalText := 2, -45, TextRotationVertical
+	tests := []struct {
+		cell  string
+		style CellStyle
+	}{
+		{"A1", CellStyle{Alignment: &AlignmentStyle{Horizontal: &horizontal, Vertical: &vertical, WrapText: &wrapText, Indent: &indent}}},
+		{"A2", CellStyle{Alignment: &AlignmentStyle{TextRotation: &downward, ShrinkToFit: &shrinkToFit}, Protection: &ProtectionStyle{Locked: &unlocked}}},
+		// Only hidden is given, so the cell stays locked
+		{"A3", CellStyle{Alignment: &AlignmentStyle{TextRotation: &verticalText}, Protection: &ProtectionStyle{Hidden: &hidden}}},
+	}
+	for _, tt := range tests {
+		if err := worksheet.SetCellStyle(tt.cell, &tt.style); err != nil {
+			t.Fatal(err)
+		}
+		got, err := worksheet.GetCellStyle(tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Alignment, tt.style.Alignment) || !reflect.DeepEqual(got.Protection, tt.style.Protection) {
+			t.Errorf("GetCellStyle(%q) = %+v, %+v, want %+v, %+v", tt.cell, got.Alignment, got.Protection, tt.style.Alignment, tt.style.Protection)
+		}
+	}
+
+	// The default alignment is omitted like the OLE backend does
+	general, bottom := HorizontalAlignmentGeneral, VerticalAlignmentBottom
+	if err := worksheet.SetCellStyle("A4", &CellStyle{Alignment: &AlignmentStyle{Horizontal: &general, Vertical: &bottom}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := worksheet.GetCellStyle("A4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Alignment != nil {
+		t.Errorf("GetCellStyle(%q).Alignment = %+v, want nil", "A4", got.Alignment)
+	}
+}
@@ -3,12 +3,14 @@ package excel
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/go-ole/go-ole"
@@ -305,6 +307,60 @@ func (o *OleWorksheet) GetFormula(cell string) (string, error) {
 	return formula, nil
 }
 
+func (o *OleWorksheet) GetCellInfo(cell string) (*CellInfo, error) {
+	text, err := o.GetValue(cell)
+	if err != nil {
+		return nil, err
+	}
+	rng := oleutil.MustGetProperty(o.worksheet, "Range", cell).ToIDispatch()
+	defer rng.Release()
+
+	info := &CellInfo{Text: text}
+	generalNumberFormat := oleutil.MustGetProperty(o.excel.application, "International", 26).Value().(string) // xlGeneralFormatName
+	if numberFormat := oleutil.MustGetProperty(rng, "NumberFormat").ToString(); numberFormat != generalNumberFormat {
+		info.NumFmt = numberFormat
+	}
+
+	// Value2 is the raw value, while Value distinguishes dates from numbers
+	switch value := oleutil.MustGetProperty(rng, "Value2").Value().(type) {
+	case float64:
+		info.Value = strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		info.Value = strings.ToUpper(strconv.FormatBool(value))
+	case string:
+		info.Value = value
+	case nil:
+		info.Value = ""
+	default:
+		// An error value has no representation other than the text
+		info.Value = text
+	}
+	var valueType CellValueType
+	switch oleutil.MustGetProperty(rng, "Value").VT {
+	case ole.VT_EMPTY, ole.VT_NULL:
+		valueType = CellValueTypeEmpty
+	case ole.VT_DATE:
+		valueType = CellValueTypeDate
+	case ole.VT_BOOL:
+		valueType = CellValueTypeBool
+	case ole.VT_BSTR:
+		valueType = CellValueTypeString
+	case ole.VT_ERROR:
+		valueType = CellValueTypeError
+	default:
+		valueType = CellValueTypeNumber
+	}
+
+	if hasFormula, _ := oleutil.MustGetProperty(rng, "HasFormula").Value().(bool); hasFormula {
+		info.Type = CellValueTypeFormula
+		info.ResultType = valueType
+		info.Formula = oleutil.MustGetProperty(rng, "Formula").ToString()
+	} else {
+		info.Type = valueType
+	}
+	return info, nil
+}
+
 func (o *OleWorksheet) GetDimension() (string, error) {
 	range_ := oleutil.MustGetProperty(o.worksheet, "UsedRange").ToIDispatch()
 	defer range_.Release()
@@ -506,6 +562,53 @@ func (o *OleWorksheet) GetCellStyle(cell string) (*CellStyle, error) {
 
 	style.Border = borderStyles
 
+	// Get Alignment information, only the values which differ from the default.
+	// Each property is null if the cells of the range (e.g. a merged area) have different values.
+	alignment := &AlignmentStyle{}
+	if value, ok := oleutil.MustGetProperty(rng, "HorizontalAlignment").Value().(int32); ok {
+		if horizontal := excelToHorizontalAlignment(value); horizontal != HorizontalAlignmentGeneral {
+			alignment.Horizontal = &horizontal
+		}
+	}
+	if value, ok := oleutil.MustGetProperty(rng, "VerticalAlignment").Value().(int32); ok {
+		if vertical := excelToVerticalAlignment(value); vertical != VerticalAlignmentBottom {
+			alignment.Vertical = &vertical
+		}
+	}
+	if wrapText, ok := oleutil.MustGetProperty(rng, "WrapText").Value().(bool); ok && wrapText {
+		alignment.WrapText = &wrapText
+	}
+	if value, ok := oleutil.MustGetProperty(rng, "IndentLevel").Value().(int32); ok && value != 0 {
+		indent := int(value)
+		alignment.Indent = &indent
+	}
+	if value, ok := oleutil.MustGetProperty(rng, "Orientation").Value().(int32); ok {
+		if textRotation := excelOrientationToTextRotation(value); textRotation != 0 {
+			alignment.TextRotation = &textRotation
+		}
+	}
+	if shrinkToFit, ok := oleutil.MustGetProperty(rng, "ShrinkToFit").Value().(bool); ok && shrinkToFit {
+		alignment.ShrinkToFit = &shrinkToFit
+	}
+	if alignment.Horizontal != nil || alignment.Vertical != nil || alignment.WrapText != nil || alignment.Indent != nil || alignment.TextRotation != nil || alignment.ShrinkToFit != nil {
+		style.Alignment = alignment
+	}
+
+	// Get Protection information, only the flags which differ from the default
+	locked, lockedOk := oleutil.MustGetProperty(rng, "Locked").Value().(bool)
+	hidden, hiddenOk := oleutil.MustGetProperty(rng, "FormulaHidden").Value().(bool)
+	unlocked := lockedOk && !locked
+	hidden = hiddenOk && hidden
+	if unlocked || hidden {
+		style.Protection = &ProtectionStyle{}
+		if unlocked {
+			style.Protection.Locked = &locked
+		}
+		if hidden {
+			style.Protection.Hidden = &hidden
+		}
+	}
+
 	// Get NumberFormat information
 	generalNumberFormat := oleutil.MustGetProperty(o.excel.application, "International", 26).Value().(string) // xlGeneralFormatName
 	numberFormat := oleutil.MustGetProperty(rng, "NumberFormat").ToString()
@@ -688,6 +791,38 @@ func (o *OleWorksheet) SetCellStyle(cell string, style *CellStyle) error {
 		}
 	}
 
+	// Apply Alignment
+	if style.Alignment != nil {
+		if style.Alignment.Horizontal != nil {
+			oleutil.PutProperty(rng, "HorizontalAlignment", horizontalAlignmentToExcel(*style.Alignment.Horizontal))
+		}
+		if style.Alignment.Vertical != nil {
+			oleutil.PutProperty(rng, "VerticalAlignment", verticalAlignmentToExcel(*style.Alignment.Vertical))
+		}
+		if style.Alignment.WrapText != nil {
+			oleutil.PutProperty(rng, "WrapText", *style.Alignment.WrapText)
+		}
+		if style.Alignment.Indent != nil {
+			oleutil.PutProperty(rng, "IndentLevel", *style.Alignment.Indent)
+		}
+		if style.Alignment.TextRotation != nil {
+			oleutil.PutProperty(rng, "Orientation", textRotationToExcelOrientation(*style.Alignment.TextRotation))
+		}
+		if style.Alignment.ShrinkToFit != nil {
+			oleutil.PutProperty(rng, "ShrinkToFit", *style.Alignment.ShrinkToFit)
+		}
+	}
+
+	// Apply Protection
+	if style.Protection != nil {
+		if style.Protection.Locked != nil {
+			oleutil.PutProperty(rng, "Locked", *style.Protection.Locked)
+		}
+		if style.Protection.Hidden != nil {
+			oleutil.PutProperty(rng, "FormulaHidden", *style.Protection.Hidden)
+		}
+	}
+
 	// Apply Number Format
 	if style.NumFmt != nil && *style.NumFmt != "" {
 		oleutil.PutProperty(rng, "NumberFormat", *style.NumFmt)
@@ -815,6 +950,106 @@ func fillPatternToExcelPattern(pattern FillPattern) int32 {
 	}
 }
 
+// horizontalAlignmentToExcel converts HorizontalAlignment to Excel XlHAlign constant
+func horizontalAlignmentToExcel(alignment HorizontalAlignment) int32 {
+	switch alignment {
+	case HorizontalAlignmentLeft:
+		return -4131 // xlLeft
+	case HorizontalAlignmentCenter:
+		return -4108 // xlCenter
+	case HorizontalAlignmentRight:
+		return -4152 // xlRight
+	case HorizontalAlignmentFill:
+		return 5 // xlFill
+	case HorizontalAlignmentJustify:
+		return -4130 // xlJustify
+	case HorizontalAlignmentCenterContinuous:
+		return 7 // xlCenterAcrossSelection
+	case HorizontalAlignmentDistributed:
+		return -4117 // xlDistributed
+	default:
+		return 1 // xlGeneral
+	}
+}
+
+// excelToHorizontalAlignment converts Excel XlHAlign constant to HorizontalAlignment
+func excelToHorizontalAlignment(excelAlignment int32) HorizontalAlignment {
+	switch excelAlignment {
+	case -4131: // xlLeft
+		return HorizontalAlignmentLeft
+	case -4108: // xlCenter
+		return HorizontalAlignmentCenter
+	case -4152: // xlRight
+		return HorizontalAlignmentRight
+	case 5: // xlFill
+		return HorizontalAlignmentFill
+	case -4130: // xlJustify
+		return HorizontalAlignmentJustify
+	case 7: // xlCenterAcrossSelection
+		return HorizontalAlignmentCenterContinuous
+	case -4117: // xlDistributed
+		return HorizontalAlignmentDistributed
+	default:
+		return HorizontalAlignmentGeneral
+	}
+}
+
+// verticalAlignmentToExcel converts VerticalAlignment to Excel XlVAlign constant
+func verticalAlignmentToExcel(alignment VerticalAlignment) int32 {
+	switch alignment {
+	case VerticalAlignmentTop:
+		return -4160 // xlTop
+	case VerticalAlignmentCenter:
+		return -4108 // xlCenter
+	case VerticalAlignmentJustify:
+		return -4130 // xlJustify
+	case VerticalAlignmentDistributed:
+		return -4117 // xlDistributed
+	default:
+		return -4107 // xlBottom
+	}
+}
+
+// excelToVerticalAlignment converts Excel XlVAlign constant to VerticalAlignment
+func excelToVerticalAlignment(excelAlignment int32) VerticalAlignment {
+	switch excelAlignment {
+	case -4160: // xlTop
+		return VerticalAlignmentTop
+	case -4108: // xlCenter
+		return VerticalAlignmentCenter
+	case -4130: // xlJustify
+		return VerticalAlignmentJustify
+	case -4117: // xlDistributed
+		return VerticalAlignmentDistributed
+	default:
+		return VerticalAlignmentBottom
+	}
+}
+
+// textRotationToExcelOrientation converts the text rotation to the Orientation of Excel
+func textRotationToExcelOrientation(textRotation int) int32 {
+	if textRotation == TextRotationVertical {
+		return -4166 // xlVertical
+	}
+	return int32(textRotation)
+}
+
+// excelOrientationToTextRotation converts the Orientation of Excel, degrees or XlOrientation constant, to the text rotation
+func excelOrientationToTextRotation(orientation int32) int {
+	switch orientation {
+	case -4128: // xlHorizontal
+		return 0
+	case -4166: // xlVertical
+		return TextRotationVertical
+	case -4171: // xlUpward
+		return 90
+	case -4170: // xlDownward
+		return -90
+	default:
+		return int(orientation)
+	}
+}
+
 func normalizePath(path string) string {
 	// Normalize the volume name to uppercase
 	vol := filepath.VolumeName(path)
@@ -867,6 +1102,37 @@ func (o *OleWorksheet) UnmergeCells(mergeRange string) error {
 	return err
 }
 
+func (o *OleWorksheet) GetMergedCells(cellRange string) ([]string, error) {
+	rng := oleutil.MustGetProperty(o.worksheet, "Range", cellRange).ToIDispatch()
+	defer rng.Release()
+	merged := []string{}
+	// MergeCells of a range is false if none of its cells are merged, and null if some of them are
+	if mergeCells, ok := oleutil.MustGetProperty(rng, "MergeCells").Value().(bool); ok && !mergeCells {
+		return merged, nil
+	}
+	startCol, startRow, endCol, endRow, err := ParseRange(cellRange)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for row := startRow; row <= endRow; row++ {
+		for col := startCol; col <= endCol; col++ {
+			cell := oleutil.MustGetProperty(o.worksheet, "Cells", row, col).ToIDispatch()
+			if mergeCells, _ := oleutil.MustGetProperty(cell, "MergeCells").Value().(bool); mergeCells {
+				mergeArea := oleutil.MustGetProperty(cell, "MergeArea").ToIDispatch()
+				address := NormalizeRange(oleutil.MustGetProperty(mergeArea, "Address").ToString())
+				mergeArea.Release()
+				if !seen[address] {
+					seen[address] = true
+					merged = append(merged, address)
+				}
+			}
+			cell.Release()
+		}
+	}
+	return merged, nil
+}
+
 func (o *OleWorksheet) SetColumnWidth(startCol, endCol string, width float64) error {
 	rangeStr := startCol + ":" + endCol
 	columns := oleutil.MustGetProperty(o.worksheet, "Columns", rangeStr).ToIDispatch()
@@ -1013,7 +1279,11 @@ func mapOleValidationType(validationType string) int {
 	}
 }
 
-func (o *OleWorksheet) FindReplace(searchRange string, find string, replace string, matchCase bool, matchEntireCell bool) (int, error) {
+func (o *OleWorksheet) FindReplace(ctx context.Context, searchRange string, find string, replace string, matchCase bool, matchEntireCell bool, progress ProgressFunc) (int, error) {
+	// Range.Replace is a single call, so cancellation is only checked before it starts
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 	var rng *ole.IDispatch
 	if searchRange != "" {
 		rng = oleutil.MustGetProperty(o.worksheet, "Range", searchRange).ToIDispatch()
@@ -1,7 +1,10 @@
 package excel
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"slices"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -50,6 +53,33 @@ func calculateFixedSizeRanges(dimension string, pageSize int) []string {
 	return ranges
 }
 
+// calculateGridRanges computes paging ranges for a given dimension and page size like calculateFixedSizeRanges,
+// but also splits the columns into blocks when a row of the dimension exceeds the page size, so that no page
+// exceeds it. Pages are ordered over, then down: all column blocks of a row band come before the next rows.
+func calculateGridRanges(dimension string, pageSize int) []string {
+	startCol, startRow, endCol, endRow, err := ParseRange(dimension)
+	if err != nil || pageSize <= 0 {
+		return []string{}
+	}
+
+	colsPerPage := endCol - startCol + 1
+	if colsPerPage > pageSize {
+		// Square blocks keep both the rows and the columns of a page readable
+		colsPerPage = max(1, int(math.Sqrt(float64(pageSize))))
+	}
+	rowsPerPage := max(1, pageSize/colsPerPage)
+
+	var ranges []string
+	for row := startRow; row <= endRow; row += rowsPerPage {
+		pageEndRow := min(row+rowsPerPage-1, endRow)
+		for col := startCol; col <= endCol; col += colsPerPage {
+			pageEndCol := min(col+colsPerPage-1, endCol)
+			ranges = append(ranges, RangeName(col, row, pageEndCol, pageEndRow))
+		}
+	}
+	return ranges
+}
+
 // ExcelizeFixedSizePagingStrategy calculates paging ranges with a fixed cell count per page.
 type ExcelizeFixedSizePagingStrategy struct {
 	pageSize  int
@@ -234,6 +264,456 @@ func (s *PrintAreaPagingStrategy) CalculatePagingRanges() []string {
 	return s.calculateRangesFromBreaks(printArea, breaks)
 }
 
+// GridPagingStrategy calculates paging ranges with a fixed cell count per page,
+// splitting very wide sheets into column blocks as well as row bands.
+type GridPagingStrategy struct {
+	pageSize  int
+	dimension string
+}
+
+// NewGridPagingStrategy creates a new GridPagingStrategy instance.
+func NewGridPagingStrategy(pageSize int, worksheet Worksheet) (*GridPagingStrategy, error) {
+	if pageSize <= 0 {
+		pageSize = 5000
+	}
+
+	if worksheet == nil {
+		return nil, fmt.Errorf("worksheet is nil")
+	}
+
+	dimension, err := worksheet.GetDimension()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GridPagingStrategy{
+		pageSize:  pageSize,
+		dimension: dimension,
+	}, nil
+}
+
+// CalculatePagingRanges generates paging ranges based on fixed cell count in both axes.
+func (s *GridPagingStrategy) CalculatePagingRanges() []string {
+	return calculateGridRanges(s.dimension, s.pageSize)
+}
+
+// HeaderRowProvider is implemented by the paging strategies which detect the header rows of a sheet,
+// so that the header row can be repeated on every page.
+type HeaderRowProvider interface {
+	// HeaderRows returns the row number of the header row of each column of the page starting at the range,
+	// or 0 for a column without a header row. Side-by-side tables may have different header rows.
+	HeaderRows(cellRange string) []int
+}
+
+// PageFinder is implemented by the paging strategies which find the paging ranges around a range
+// without calculating all the paging ranges of the sheet, which is expensive for them.
+type PageFinder interface {
+	// FirstRange returns the first paging range, or "" if there is none.
+	FirstRange() string
+	// NextRange returns the paging range after the range, or "" if it is the last one.
+	NextRange(cellRange string) string
+}
+
+// rowSpan is the rows from start to end.
+type rowSpan struct {
+	start int
+	end   int
+}
+
+// tableRowSpans returns the rows of the tables clipped to the dimension, sorted and merged where they overlap.
+func tableRowSpans(dimension string, tableRanges []string) []rowSpan {
+	_, startRow, _, endRow, err := ParseRange(dimension)
+	if err != nil {
+		return nil
+	}
+	var spans []rowSpan
+	for _, tableRange := range tableRanges {
+		_, tableStartRow, _, tableEndRow, err := ParseRange(tableRange)
+		if err != nil {
+			continue
+		}
+		span := rowSpan{start: max(tableStartRow, startRow), end: min(tableEndRow, endRow)}
+		if span.start <= span.end {
+			spans = append(spans, span)
+		}
+	}
+	slices.SortFunc(spans, func(a, b rowSpan) int {
+		return a.start - b.start
+	})
+	var merged []rowSpan
+	for _, span := range spans {
+		if len(merged) > 0 && span.start <= merged[len(merged)-1].end {
+			merged[len(merged)-1].end = max(merged[len(merged)-1].end, span.end)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// calculateTableAwareRanges computes paging ranges like calculateFixedSizeRanges, but no page spans the boundary
+// of a table: the rows of each table and the rows between the tables are paged separately.
+func calculateTableAwareRanges(dimension string, tableRanges []string, pageSize int) []string {
+	startCol, startRow, endCol, endRow, err := ParseRange(dimension)
+	if err != nil {
+		return []string{}
+	}
+
+	var segments []rowSpan
+	currentRow := startRow
+	for _, span := range tableRowSpans(dimension, tableRanges) {
+		if currentRow < span.start {
+			segments = append(segments, rowSpan{start: currentRow, end: span.start - 1})
+		}
+		segments = append(segments, span)
+		currentRow = span.end + 1
+	}
+	if currentRow <= endRow {
+		segments = append(segments, rowSpan{start: currentRow, end: endRow})
+	}
+
+	ranges := []string{}
+	for _, segment := range segments {
+		ranges = append(ranges, calculateFixedSizeRanges(RangeName(startCol, segment.start, endCol, segment.end), pageSize)...)
+	}
+	return ranges
+}
+
+// TableAwarePagingStrategy calculates paging ranges with a fixed cell count per page aligned to the tables of
+// the sheet. The header row of each column of a page is the first row of its table, or the first row of the used
+// range if the sheet has no table.
+type TableAwarePagingStrategy struct {
+	pageSize    int
+	dimension   string
+	tableRanges []string
+}
+
+// NewTableAwarePagingStrategy creates a new TableAwarePagingStrategy instance.
+func NewTableAwarePagingStrategy(pageSize int, worksheet Worksheet) (*TableAwarePagingStrategy, error) {
+	if pageSize <= 0 {
+		pageSize = 5000
+	}
+
+	if worksheet == nil {
+		return nil, fmt.Errorf("worksheet is nil")
+	}
+
+	dimension, err := worksheet.GetDimension()
+	if err != nil {
+		return nil, err
+	}
+	tables, err := worksheet.GetTables()
+	if err != nil {
+		return nil, err
+	}
+	tableRanges := make([]string, len(tables))
+	for i, table := range tables {
+		tableRanges[i] = table.Range
+	}
+
+	return &TableAwarePagingStrategy{
+		pageSize:    pageSize,
+		dimension:   dimension,
+		tableRanges: tableRanges,
+	}, nil
+}
+
+// CalculatePagingRanges generates paging ranges based on fixed cell count within each table.
+func (s *TableAwarePagingStrategy) CalculatePagingRanges() []string {
+	return calculateTableAwareRanges(s.dimension, s.tableRanges, s.pageSize)
+}
+
+// HeaderRows returns the first row of the table which each column of the range starts in, so that
+// side-by-side tables repeat their own header rows. The columns outside of the tables have no header row,
+// unless the sheet has no table at all.
+func (s *TableAwarePagingStrategy) HeaderRows(cellRange string) []int {
+	startCol, startRow, endCol, _, err := ParseRange(cellRange)
+	if err != nil {
+		return nil
+	}
+	rows := make([]int, endCol-startCol+1)
+	if len(s.tableRanges) == 0 {
+		_, usedStartRow, _, _, err := ParseRange(s.dimension)
+		if err != nil || startRow < usedStartRow {
+			return rows
+		}
+		for i := range rows {
+			rows[i] = usedStartRow
+		}
+		return rows
+	}
+	for _, tableRange := range s.tableRanges {
+		tableStartCol, tableStartRow, tableEndCol, tableEndRow, err := ParseRange(tableRange)
+		if err != nil || startRow < tableStartRow || tableEndRow < startRow {
+			continue
+		}
+		for col := max(startCol, tableStartCol); col <= min(endCol, tableEndCol); col++ {
+			rows[col-startCol] = tableStartRow
+		}
+	}
+	return rows
+}
+
+// charsPerToken is the average number of ASCII characters per token
+const charsPerToken = 4
+
+// TokenEstimate is the rough size of the rendered table used to estimate the tokens of a page,
+// which depends on the output format.
+type TokenEstimate struct {
+	// CellOverhead is the tokens of the markup around a cell value, e.g. "<td></td>"
+	CellOverhead int
+	// RowOverhead is the tokens of the markup and the row number of a row, e.g. "<tr><th>1</th></tr>"
+	RowOverhead int
+	// StyleOverhead is the tokens of the style reference of a non-empty cell, or 0 if styles are not rendered
+	StyleOverhead int
+	// Formula estimates the formulas instead of the values of the cells
+	Formula bool
+}
+
+// DefaultTokenEstimate is the size of the HTML output of the values.
+var DefaultTokenEstimate = TokenEstimate{CellOverhead: 2, RowOverhead: 5}
+
+// estimateTokens estimates the number of tokens of a text.
+// ASCII characters are counted as charsPerToken characters per token and other characters
+// (e.g. CJK) as one token each, since they are rarely merged into a token.
+func estimateTokens(text string) int {
+	ascii := 0
+	others := 0
+	for _, r := range text {
+		if r < 0x80 {
+			ascii++
+		} else {
+			others++
+		}
+	}
+	return (ascii+charsPerToken-1)/charsPerToken + others
+}
+
+// calculateTokenBudgetRanges computes paging ranges which fit in the token budget.
+// rowTokens holds the estimated tokens of each row of the dimension.
+// A row exceeding the budget by itself makes up a page.
+func calculateTokenBudgetRanges(dimension string, rowTokens []int, budget int) []string {
+	startCol, startRow, endCol, endRow, err := ParseRange(dimension)
+	if err != nil || len(rowTokens) != endRow-startRow+1 {
+		return []string{}
+	}
+
+	var ranges []string
+	pageStartRow := startRow
+	pageTokens := 0
+	for row := startRow; row <= endRow; row++ {
+		tokens := rowTokens[row-startRow]
+		if row > pageStartRow && pageTokens+tokens > budget {
+			ranges = append(ranges, RangeName(startCol, pageStartRow, endCol, row-1))
+			pageStartRow = row
+			pageTokens = 0
+		}
+		pageTokens += tokens
+	}
+	ranges = append(ranges, RangeName(startCol, pageStartRow, endCol, endRow))
+	return ranges
+}
+
+// calculateRowBudgetRanges computes the paging ranges of calculateTokenBudgetRanges with the size of each row
+// given by rowSize, which returns false if the calculation is cancelled. It returns no range if cancelled.
+func calculateRowBudgetRanges(dimension string, budget int, rowSize func(row int) (int, bool)) []string {
+	_, startRow, _, endRow, err := ParseRange(dimension)
+	if err != nil {
+		return []string{}
+	}
+	rowSizes := make([]int, 0, endRow-startRow+1)
+	for row := startRow; row <= endRow; row++ {
+		size, ok := rowSize(row)
+		if !ok {
+			return []string{}
+		}
+		rowSizes = append(rowSizes, size)
+	}
+	return calculateTokenBudgetRanges(dimension, rowSizes, budget)
+}
+
+// findRowBudgetRange returns the paging range of calculateRowBudgetRanges after the range, or the first one
+// if the range is empty, calling rowSize for the rows of the page only. The range must span all the columns
+// of the dimension like the paging ranges. It returns "" if there is no such range or the calculation is cancelled.
+func findRowBudgetRange(dimension string, cellRange string, budget int, rowSize func(row int) (int, bool)) string {
+	startCol, pageStartRow, endCol, endRow, err := ParseRange(dimension)
+	if err != nil {
+		return ""
+	}
+	if cellRange != "" {
+		rangeStartCol, _, rangeEndCol, rangeEndRow, err := ParseRange(cellRange)
+		if err != nil || rangeStartCol != startCol || rangeEndCol != endCol || rangeEndRow >= endRow {
+			return ""
+		}
+		pageStartRow = rangeEndRow + 1
+	}
+	pageSize := 0
+	for row := pageStartRow; row <= endRow; row++ {
+		size, ok := rowSize(row)
+		if !ok {
+			return ""
+		}
+		if row > pageStartRow && pageSize+size > budget {
+			return RangeName(startCol, pageStartRow, endCol, row-1)
+		}
+		pageSize += size
+	}
+	return RangeName(startCol, pageStartRow, endCol, endRow)
+}
+
+// TokenBudgetPagingStrategy calculates paging ranges so that the rendered output of each page
+// fits in a token budget. The tokens of each row are estimated from the values (or the formulas)
+// of its cells, so a page of long texts has fewer rows than a page of short values or empty cells.
+type TokenBudgetPagingStrategy struct {
+	// ctx cancels reading the cells, which may visit all the cells of the sheet
+	ctx       context.Context
+	budget    int
+	estimate  TokenEstimate
+	worksheet Worksheet
+	dimension string
+}
+
+// NewTokenBudgetPagingStrategy creates a new TokenBudgetPagingStrategy instance.
+func NewTokenBudgetPagingStrategy(ctx context.Context, budget int, estimate TokenEstimate, worksheet Worksheet) (*TokenBudgetPagingStrategy, error) {
+	if budget <= 0 {
+		budget = 10000
+	}
+
+	if worksheet == nil {
+		return nil, fmt.Errorf("worksheet is nil")
+	}
+
+	dimension, err := worksheet.GetDimension()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenBudgetPagingStrategy{
+		ctx:       ctx,
+		budget:    budget,
+		estimate:  estimate,
+		worksheet: worksheet,
+		dimension: dimension,
+	}, nil
+}
+
+// CalculatePagingRanges generates paging ranges based on the estimated tokens of the rows.
+// It reads all the cells of the sheet, and returns no range if the context is cancelled.
+func (s *TokenBudgetPagingStrategy) CalculatePagingRanges() []string {
+	return calculateRowBudgetRanges(s.dimension, s.budget, s.rowTokens)
+}
+
+// FirstRange returns the first paging range, reading the cells of the first page only.
+func (s *TokenBudgetPagingStrategy) FirstRange() string {
+	return findRowBudgetRange(s.dimension, "", s.budget, s.rowTokens)
+}
+
+// NextRange returns the paging range after the range, reading the cells of the next page only.
+func (s *TokenBudgetPagingStrategy) NextRange(cellRange string) string {
+	return findRowBudgetRange(s.dimension, cellRange, s.budget, s.rowTokens)
+}
+
+// rowTokens estimates the tokens of the row. It returns false if the context is cancelled.
+func (s *TokenBudgetPagingStrategy) rowTokens(row int) (int, bool) {
+	if s.ctx.Err() != nil {
+		return 0, false
+	}
+	startCol, _, endCol, _, err := ParseRange(s.dimension)
+	if err != nil {
+		return 0, false
+	}
+	extractor := s.worksheet.GetValue
+	if s.estimate.Formula {
+		extractor = s.worksheet.GetFormula
+	}
+	tokens := s.estimate.RowOverhead
+	for col := startCol; col <= endCol; col++ {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return 0, false
+		}
+		// A cell which cannot be read is rendered as empty
+		value, _ := extractor(cell)
+		tokens += s.estimate.CellOverhead + estimateTokens(value)
+		if value != "" {
+			tokens += s.estimate.StyleOverhead
+		}
+	}
+	return tokens, true
+}
+
+// NonEmptyCellsPagingStrategy calculates paging ranges by the number of non-empty cells,
+// so that the empty rows between the contents of a sparse sheet do not take up the pages.
+type NonEmptyCellsPagingStrategy struct {
+	// ctx cancels reading the cells, which may visit all the cells of the sheet
+	ctx       context.Context
+	pageSize  int
+	worksheet Worksheet
+	dimension string
+}
+
+// NewNonEmptyCellsPagingStrategy creates a new NonEmptyCellsPagingStrategy instance.
+func NewNonEmptyCellsPagingStrategy(ctx context.Context, pageSize int, worksheet Worksheet) (*NonEmptyCellsPagingStrategy, error) {
+	if pageSize <= 0 {
+		pageSize = 5000
+	}
+
+	if worksheet == nil {
+		return nil, fmt.Errorf("worksheet is nil")
+	}
+
+	dimension, err := worksheet.GetDimension()
+	if err != nil {
+		return nil, err
+	}
+
+	return &NonEmptyCellsPagingStrategy{
+		ctx:       ctx,
+		pageSize:  pageSize,
+		worksheet: worksheet,
+		dimension: dimension,
+	}, nil
+}
+
+// CalculatePagingRanges generates paging ranges based on the number of non-empty cells of the rows.
+// It reads all the cells of the sheet, and returns no range if the context is cancelled.
+func (s *NonEmptyCellsPagingStrategy) CalculatePagingRanges() []string {
+	// The number of cells is a budget as well as the tokens
+	return calculateRowBudgetRanges(s.dimension, s.pageSize, s.rowCells)
+}
+
+// FirstRange returns the first paging range, reading the cells of the first page only.
+func (s *NonEmptyCellsPagingStrategy) FirstRange() string {
+	return findRowBudgetRange(s.dimension, "", s.pageSize, s.rowCells)
+}
+
+// NextRange returns the paging range after the range, reading the cells of the next page only.
+func (s *NonEmptyCellsPagingStrategy) NextRange(cellRange string) string {
+	return findRowBudgetRange(s.dimension, cellRange, s.pageSize, s.rowCells)
+}
+
+// rowCells counts the non-empty cells of the row. It returns false if the context is cancelled.
+func (s *NonEmptyCellsPagingStrategy) rowCells(row int) (int, bool) {
+	if s.ctx.Err() != nil {
+		return 0, false
+	}
+	startCol, _, endCol, _, err := ParseRange(s.dimension)
+	if err != nil {
+		return 0, false
+	}
+	cells := 0
+	for col := startCol; col <= endCol; col++ {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return 0, false
+		}
+		if value, _ := s.worksheet.GetValue(cell); value != "" {
+			cells++
+		}
+	}
+	return cells, true
+}
+
 // PagingRangeService provides paging operations.
 type PagingRangeService struct {
 	strategy PagingStrategy
@@ -279,3 +759,43 @@ func (s *PagingRangeService) FindNextRange(allRanges []string, currentRange stri
 	}
 	return ""
 }
+
+// FindNextRangeRight returns the range on the right of the current range, which covers the same rows.
+func (s *PagingRangeService) FindNextRangeRight(allRanges []string, currentRange string) string {
+	return findAdjacentRange(allRanges, currentRange, func(current, candidate rangeBounds) bool {
+		return candidate.startRow == current.startRow && candidate.endRow == current.endRow && candidate.startCol == current.endCol+1
+	})
+}
+
+// FindNextRangeDown returns the range below the current range, which covers the same columns.
+func (s *PagingRangeService) FindNextRangeDown(allRanges []string, currentRange string) string {
+	return findAdjacentRange(allRanges, currentRange, func(current, candidate rangeBounds) bool {
+		return candidate.startCol == current.startCol && candidate.endCol == current.endCol && candidate.startRow == current.endRow+1
+	})
+}
+
+type rangeBounds struct {
+	startCol int
+	startRow int
+	endCol   int
+	endRow   int
+}
+
+func parseRangeBounds(cellRange string) (rangeBounds, error) {
+	startCol, startRow, endCol, endRow, err := ParseRange(cellRange)
+	return rangeBounds{startCol, startRow, endCol, endRow}, err
+}
+
+func findAdjacentRange(allRanges []string, currentRange string, isAdjacent func(current, candidate rangeBounds) bool) string {
+	current, err := parseRangeBounds(currentRange)
+	if err != nil {
+		return ""
+	}
+	for _, r := range allRanges {
+		candidate, err := parseRangeBounds(r)
+		if err == nil && isAdjacent(current, candidate) {
+			return r
+		}
+	}
+	return ""
+}
@@ -1,7 +1,11 @@
 package excel
 
 import (
+	"context"
+	"slices"
 	"testing"
+
+	"github.com/xuri/excelize/v2"
 )
 
 func TestCalculateFixedSizeRanges(t *testing.T) {
@@ -164,3 +168,383 @@ func TestPagingRangeService_FilterRemainingPagingRanges(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcdefgh", 2},
+		{"abcde", 2},
+		{"売上合計", 4},
+		{"ID 売上", 3},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.text); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateTokenBudgetRanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		dimension string
+		rowTokens []int
+		budget    int
+		want      []string
+	}{
+		{
+			name:      "single page fits all",
+			dimension: "A1:C3",
+			rowTokens: []int{10, 10, 10},
+			budget:    100,
+			want:      []string{"A1:C3"},
+		},
+		{
+			name:      "long rows make small pages",
+			dimension: "A1:B6",
+			rowTokens: []int{10, 10, 80, 10, 10, 10},
+			budget:    30,
+			want:      []string{"A1:B2", "A3:B3", "A4:B6"},
+		},
+		{
+			name:      "rows fill the budget exactly",
+			dimension: "B2:C5",
+			rowTokens: []int{5, 5, 5, 5},
+			budget:    10,
+			want:      []string{"B2:C3", "B4:C5"},
+		},
+		{
+			name:      "row count mismatch returns empty",
+			dimension: "A1:A3",
+			rowTokens: []int{1},
+			budget:    10,
+			want:      []string{},
+		},
+		{
+			name:      "invalid dimension returns empty",
+			dimension: "invalid",
+			rowTokens: []int{},
+			budget:    10,
+			want:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateTokenBudgetRanges(tt.dimension, tt.rowTokens, tt.budget)
+			if len(got) != len(tt.want) {
+				t.Errorf("calculateTokenBudgetRanges(%q, %v, %d) returned %d ranges, want %d: got %v",
+					tt.dimension, tt.rowTokens, tt.budget, len(got), len(tt.want), got)
+				return
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("calculateTokenBudgetRanges(%q, %v, %d)[%d] = %q, want %q",
+						tt.dimension, tt.rowTokens, tt.budget, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBudgetPagingStrategy(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	worksheet, err := NewExcelizeExcel(file).FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worksheet.Release()
+	for _, cell := range []string{"A1", "B1", "A2", "B2", "A3", "A4", "B4"} {
+		if err := worksheet.SetValue(cell, "1234"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A formula much longer than its value
+	if err := worksheet.SetFormula("B3", `=IF(A3="1234","1234","some very long alternative text")`); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		estimate TokenEstimate
+		want     []string
+	}{
+		// Each row is 5 + 2 * (2 + 1) tokens
+		{"html values", context.Background(), DefaultTokenEstimate, []string{"A1:B2", "A3:B4"}},
+		{"html formulas", context.Background(), TokenEstimate{CellOverhead: 2, RowOverhead: 5, Formula: true}, []string{"A1:B2", "A3:B3", "A4:B4"}},
+		{"html styles", context.Background(), TokenEstimate{CellOverhead: 2, RowOverhead: 5, StyleOverhead: 4}, []string{"A1:B1", "A2:B2", "A3:B3", "A4:B4"}},
+		// Each row is 1 + 2 * (1 + 1) tokens
+		{"csv values", context.Background(), TokenEstimate{CellOverhead: 1, RowOverhead: 1}, []string{"A1:B4"}},
+		{"cancelled", cancelled, DefaultTokenEstimate, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := NewTokenBudgetPagingStrategy(tt.ctx, 22, tt.estimate, worksheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strategy.CalculatePagingRanges(); !slices.Equal(got, tt.want) {
+				t.Errorf("CalculatePagingRanges() = %v, want %v", got, tt.want)
+			}
+			// Finding the pages one by one gives the same pages
+			got := []string{}
+			for page := strategy.FirstRange(); page != ""; page = strategy.NextRange(page) {
+				got = append(got, page)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FirstRange() and NextRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// countingWorksheet counts the cells read from the worksheet.
+type countingWorksheet struct {
+	Worksheet
+	reads []string
+}
+
+func (w *countingWorksheet) GetValue(cell string) (string, error) {
+	w.reads = append(w.reads, cell)
+	return w.Worksheet.GetValue(cell)
+}
+
+func TestTokenBudgetPagingStrategy_NextRangeReadsNextPageOnly(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	sheet, err := NewExcelizeExcel(file).FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sheet.Release()
+	for row := 1; row <= 100; row++ {
+		cell, _ := excelize.CoordinatesToCellName(2, row)
+		if err := sheet.SetValue(cell, "1234"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	worksheet := &countingWorksheet{Worksheet: sheet}
+	// Each row is 5 + 2 * 2 + 1 tokens, so a page has 2 rows
+	strategy, err := NewTokenBudgetPagingStrategy(context.Background(), 22, DefaultTokenEstimate, worksheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strategy.NextRange("A51:B52"); got != "A53:B54" {
+		t.Errorf("NextRange() = %q, want %q", got, "A53:B54")
+	}
+	// The rows of the next page and the row after it
+	want := []string{"A53", "B53", "A54", "B54", "A55", "B55"}
+	if !slices.Equal(worksheet.reads, want) {
+		t.Errorf("read cells = %v, want %v", worksheet.reads, want)
+	}
+	for _, cellRange := range []string{"A99:B100", "B51:B52", "invalid"} {
+		if got := strategy.NextRange(cellRange); got != "" {
+			t.Errorf("NextRange(%q) = %q, want none", cellRange, got)
+		}
+	}
+}
+
+func TestNonEmptyCellsPagingStrategy(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	worksheet, err := NewExcelizeExcel(file).FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worksheet.Release()
+	// The empty rows between the contents do not count
+	for _, cell := range []string{"A1", "B1", "A2", "B20", "A40", "B40", "A41"} {
+		if err := worksheet.SetValue(cell, "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"pages by non-empty cells", context.Background(), []string{"A1:B19", "A20:B40", "A41:B41"}},
+		{"cancelled", cancelled, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := NewNonEmptyCellsPagingStrategy(tt.ctx, 3, worksheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strategy.CalculatePagingRanges(); !slices.Equal(got, tt.want) {
+				t.Errorf("CalculatePagingRanges() = %v, want %v", got, tt.want)
+			}
+			got := []string{}
+			for page := strategy.FirstRange(); page != ""; page = strategy.NextRange(page) {
+				got = append(got, page)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FirstRange() and NextRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateGridRanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		dimension string
+		pageSize  int
+		want      []string
+	}{
+		{
+			name:      "narrow sheet is paged by rows only",
+			dimension: "A1:C10",
+			pageSize:  9,
+			want:      []string{"A1:C3", "A4:C6", "A7:C9", "A10:C10"},
+		},
+		{
+			name:      "wide sheet is split into column blocks over, then down",
+			dimension: "A1:E3",
+			pageSize:  4, // 5 cols exceed the page size, so the blocks are 2 cols x 2 rows
+			want:      []string{"A1:B2", "C1:D2", "E1:E2", "A3:B3", "C3:D3", "E3:E3"},
+		},
+		{
+			name:      "single row wider than the page size",
+			dimension: "B2:K2",
+			pageSize:  9,
+			want:      []string{"B2:D2", "E2:G2", "H2:J2", "K2:K2"},
+		},
+		{
+			name:      "invalid dimension returns empty",
+			dimension: "invalid",
+			pageSize:  100,
+			want:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateGridRanges(tt.dimension, tt.pageSize)
+			if len(got) != len(tt.want) {
+				t.Errorf("calculateGridRanges(%q, %d) returned %d ranges, want %d: got %v",
+					tt.dimension, tt.pageSize, len(got), len(tt.want), got)
+				return
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("calculateGridRanges(%q, %d)[%d] = %q, want %q",
+						tt.dimension, tt.pageSize, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPagingRangeService_FindNextRangeRightAndDown(t *testing.T) {
+	service := NewPagingRangeService(nil)
+	allRanges := []string{"A1:B2", "C1:D2", "E1:E2", "A3:B3", "C3:D3", "E3:E3"}
+
+	tests := []struct {
+		currentRange string
+		wantRight    string
+		wantDown     string
+	}{
+		{currentRange: "A1:B2", wantRight: "C1:D2", wantDown: "A3:B3"},
+		{currentRange: "E1:E2", wantRight: "", wantDown: "E3:E3"},
+		{currentRange: "C3:D3", wantRight: "E3:E3", wantDown: ""},
+		{currentRange: "invalid", wantRight: "", wantDown: ""},
+	}
+	for _, tt := range tests {
+		if got := service.FindNextRangeRight(allRanges, tt.currentRange); got != tt.wantRight {
+			t.Errorf("FindNextRangeRight(%q) = %q, want %q", tt.currentRange, got, tt.wantRight)
+		}
+		if got := service.FindNextRangeDown(allRanges, tt.currentRange); got != tt.wantDown {
+			t.Errorf("FindNextRangeDown(%q) = %q, want %q", tt.currentRange, got, tt.wantDown)
+		}
+	}
+}
+
+func TestCalculateTableAwareRanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		dimension   string
+		tableRanges []string
+		pageSize    int
+		want        []string
+	}{
+		{
+			name:      "no table is paged like fixed size",
+			dimension: "A1:B6",
+			pageSize:  6,
+			want:      []string{"A1:B3", "A4:B6"},
+		},
+		{
+			name:        "pages are aligned to the tables",
+			dimension:   "A1:B12",
+			tableRanges: []string{"A8:B12", "A2:B5"},
+			pageSize:    6,
+			want:        []string{"A1:B1", "A2:B4", "A5:B5", "A6:B7", "A8:B10", "A11:B12"},
+		},
+		{
+			name:        "overlapping tables make up a segment",
+			dimension:   "A1:D4",
+			tableRanges: []string{"A1:B3", "C2:D4"},
+			pageSize:    100,
+			want:        []string{"A1:D4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateTableAwareRanges(tt.dimension, tt.tableRanges, tt.pageSize)
+			if len(got) != len(tt.want) {
+				t.Errorf("calculateTableAwareRanges(%q, %v, %d) returned %d ranges, want %d: got %v",
+					tt.dimension, tt.tableRanges, tt.pageSize, len(got), len(tt.want), got)
+				return
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("calculateTableAwareRanges(%q, %v, %d)[%d] = %q, want %q",
+						tt.dimension, tt.tableRanges, tt.pageSize, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTableAwarePagingStrategy_HeaderRows(t *testing.T) {
+	withTables := &TableAwarePagingStrategy{pageSize: 6, dimension: "A1:B12", tableRanges: []string{"A2:B5", "A8:B12"}}
+	withoutTables := &TableAwarePagingStrategy{pageSize: 6, dimension: "A3:B12"}
+	sideBySide := &TableAwarePagingStrategy{pageSize: 100, dimension: "A1:E20", tableRanges: []string{"A1:B10", "D3:E20"}}
+
+	tests := []struct {
+		strategy  *TableAwarePagingStrategy
+		cellRange string
+		want      []int
+	}{
+		{withTables, "A2:B4", []int{2, 2}},
+		{withTables, "A5:B5", []int{2, 2}},
+		{withTables, "A11:B12", []int{8, 8}},
+		{withTables, "A6:B7", []int{0, 0}},
+		{withoutTables, "A7:B9", []int{3, 3}},
+		{withoutTables, "invalid", nil},
+		// Each table repeats its own header row over its columns
+		{sideBySide, "A5:E10", []int{1, 1, 0, 3, 3}},
+		{sideBySide, "A11:E20", []int{0, 0, 0, 3, 3}},
+		{sideBySide, "D11:E20", []int{3, 3}},
+	}
+	for _, tt := range tests {
+		if got := tt.strategy.HeaderRows(tt.cellRange); !slices.Equal(got, tt.want) {
+			t.Errorf("HeaderRows(%q) with tables %v = %v, want %v", tt.cellRange, tt.strategy.tableRanges, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// backupGenerations is the number of previous versions kept when a workbook is saved
+var backupGenerations atomic.Int32
+
+// SetBackupGenerations sets the number of previous versions kept as backups when a workbook is saved.
+// The latest previous version is kept as "book.bak1.xlsx", the one before as "book.bak2.xlsx" and so on.
+// 0 disables backups.
+func SetBackupGenerations(generations int) {
+	backupGenerations.Store(int32(generations))
+}
+
+// BackupPath returns the path of the backup of the specified generation (1 is the latest).
+func BackupPath(path string, generation int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.bak%d%s", strings.TrimSuffix(path, ext), generation, ext)
+}
+
+// WriteFileAtomically writes the content to a temporary file in the same directory,
+// syncs it and renames it over the path. The previous version is kept as a backup
+// when enabled with SetBackupGenerations.
+func WriteFileAtomically(path string, write func(io.Writer) error) error {
+	dir := filepath.Dir(path)
+	mode := os.FileMode(0o644)
+	info, statErr := os.Stat(path)
+	if statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return err
+	}
+
+	if generations := int(backupGenerations.Load()); generations > 0 && statErr == nil {
+		if err := rotateBackups(path, generations); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// rotateBackups shifts the existing backups by one generation and keeps the current file as the latest backup.
+func rotateBackups(path string, generations int) error {
+	if err := os.Remove(BackupPath(path, generations)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for generation := generations - 1; generation >= 1; generation-- {
+		if err := os.Rename(BackupPath(path, generation), BackupPath(path, generation+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	// A hard link keeps the current file in place until the new version is renamed over it
+	latest := BackupPath(path, 1)
+	if err := os.Link(path, latest); err != nil {
+		return CopyFile(path, latest)
+	}
+	return nil
+}
+
+// CopyFile copies src to dst with the permissions of src. dst is removed if the copy fails.
+func CopyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir makes the rename durable. Not supported on every platform, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	d.Sync()
+}
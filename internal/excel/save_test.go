@@ -0,0 +1,109 @@
+package excel
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeString(content string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.xlsx")
+	if err := os.WriteFile(path, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomically(path, writeString("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "v2" {
+		t.Errorf("content = %q, want %q", got, "v2")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to be left, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomicallyKeepsOriginalOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.xlsx")
+	if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteFileAtomically(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return io.ErrUnexpectedEOF
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := readFile(t, path); got != "original" {
+		t.Errorf("content = %q, want %q", got, "original")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected the temporary file to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomicallyRotatesBackups(t *testing.T) {
+	SetBackupGenerations(2)
+	defer SetBackupGenerations(0)
+
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		if err := WriteFileAtomically(path, writeString(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := readFile(t, path); got != "v4" {
+		t.Errorf("content = %q, want %q", got, "v4")
+	}
+	if got := readFile(t, BackupPath(path, 1)); got != "v3" {
+		t.Errorf("backup 1 = %q, want %q", got, "v3")
+	}
+	if got := readFile(t, BackupPath(path, 2)); got != "v2" {
+		t.Errorf("backup 2 = %q, want %q", got, "v2")
+	}
+	if _, err := os.Stat(BackupPath(path, 3)); !os.IsNotExist(err) {
+		t.Errorf("expected no third backup, got %v", err)
+	}
+}
+
+func TestBackupPath(t *testing.T) {
+	got := BackupPath(filepath.Join("dir", "book.xlsx"), 2)
+	if want := filepath.Join("dir", "book.bak2.xlsx"); got != want {
+		t.Errorf("BackupPath() = %q, want %q", got, want)
+	}
+	if !strings.HasSuffix(BackupPath("book", 1), "book.bak1") {
+		t.Errorf("BackupPath() without extension = %q", BackupPath("book", 1))
+	}
+}
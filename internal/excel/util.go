@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -34,6 +35,13 @@ func ParseRange(rangeStr string) (int, int, int, int, error) {
 	return startCol, startRow, endCol, endRow, nil
 }
 
+// RangeName formats the coordinates of a range as a range string (e.g. A1:C10)
+func RangeName(startCol int, startRow int, endCol int, endRow int) string {
+	startCell, _ := excelize.CoordinatesToCellName(startCol, startRow)
+	endCell, _ := excelize.CoordinatesToCellName(endCol, endRow)
+	return fmt.Sprintf("%s:%s", startCell, endCell)
+}
+
 func NormalizeRange(rangeStr string) string {
 	startCol, startRow, endCol, endRow, err := ParseRange(rangeStr)
 	if err != nil {
@@ -50,6 +58,67 @@ func NormalizeRange(rangeStr string) string {
 	return fmt.Sprintf("%s:%s", startCell, endCell)
 }
 
+// builtInNumFmts is the number format codes of the built-in number format IDs which are common to all locales.
+var builtInNumFmts = map[int]string{
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	9:  "0%",
+	10: "0.00%",
+	11: "0.00E+00",
+	12: "# ?/?",
+	13: "# ??/??",
+	14: "mm-dd-yy",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm AM/PM",
+	19: "h:mm:ss AM/PM",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "m/d/yy h:mm",
+	37: "#,##0 ;(#,##0)",
+	38: "#,##0 ;[Red](#,##0)",
+	39: "#,##0.00;(#,##0.00)",
+	40: "#,##0.00;[Red](#,##0.00)",
+	45: "mm:ss",
+	46: "[h]:mm:ss",
+	47: "mm:ss.0",
+	48: "##0.0E+0",
+	49: "@",
+}
+
+// builtInDateNumFmtIDs are the built-in number format IDs of the dates in the East Asian locales.
+// Their format codes depend on the locale, so they are not in builtInNumFmts.
+var builtInDateNumFmtIDs = map[int]bool{
+	27: true, 28: true, 29: true, 30: true, 31: true, 32: true, 33: true, 34: true, 35: true, 36: true,
+	50: true, 51: true, 52: true, 53: true, 54: true, 55: true, 56: true, 57: true, 58: true,
+}
+
+var numFmtLiteralRegexp = regexp.MustCompile(`"[^"]*"|\\.|_.|\*.`)
+
+// isDateNumFmt returns true if the number format code formats a number as a date or a time.
+func isDateNumFmt(numFmt string) bool {
+	code := numFmtLiteralRegexp.ReplaceAllString(numFmt, "")
+	// Elapsed time such as [h] is a date part, while the others in brackets such as colors and locales are not
+	for {
+		start := strings.Index(code, "[")
+		end := strings.Index(code, "]")
+		if start < 0 || end < start {
+			break
+		}
+		section := strings.ToLower(code[start+1 : end])
+		if strings.Trim(section, "hms") == "" {
+			section = "h"
+		} else {
+			section = ""
+		}
+		code = code[:start] + section + code[end+1:]
+	}
+	return strings.ContainsAny(strings.ToLower(code), "ymdhs")
+}
+
 // FileIsNotWritable checks if a file is not writable
 func FileIsNotWritable(absolutePath string) bool {
 	f, err := os.OpenFile(path.Clean(absolutePath), os.O_WRONLY, os.ModePerm)
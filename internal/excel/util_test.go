@@ -114,3 +114,28 @@ func TestNormalizeRange(t *testing.T) {
 		})
 	}
 }
+
+func TestIsDateNumFmt(t *testing.T) {
+	tests := []struct {
+		numFmt string
+		want   bool
+	}{
+		{"", false},
+		{"0.00", false},
+		{"#,##0", false},
+		{"[Red]#,##0.00", false},
+		{`"days" 0`, false},
+		{`0\d`, false},
+		{"@", false},
+		{"yyyy-mm-dd", true},
+		{"m/d/yy h:mm", true},
+		{"[h]:mm:ss", true},
+		{"[$-409]mmmm d, yyyy", true},
+		{`yyyy"年"m"月"d"日"`, true},
+	}
+	for _, tt := range tests {
+		if got := isDateNumFmt(tt.numFmt); got != tt.want {
+			t.Errorf("isDateNumFmt(%q) = %v, want %v", tt.numFmt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package excel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// workbookCache keeps workbooks opened with excelize in memory so that consecutive
+// tool calls on the same file do not parse it again. Saving a cached workbook keeps the
+// serialized content in memory, which is written to disk when the workbook is flushed
+// or evicted from the cache. A cached workbook without unsaved changes is reused only
+// while the file on disk has the same modification time and size as when it was loaded
+// or written by this process. Unsaved changes are not written over a file which was changed
+// on disk in the meantime; ErrWorkbookChangedOnDisk is reported instead.
+type workbookCache struct {
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	// lockWorkbook holds off the tool calls on the workbook while it is written outside of a tool call, or nil
+	lockWorkbook WorkbookLocker
+	entries      map[string]*cachedWorkbook // key: cleaned absolute path
+	stop         chan struct{}
+}
+
+// WorkbookLocker acquires the lock which tool calls writing the workbook hold, and returns the function to release it.
+type WorkbookLocker func(absoluteFilePath string) (func(), error)
+
+// ErrWorkbookChangedOnDisk is returned when the unsaved changes of a cached workbook are not written
+// because the file was changed on disk after it was loaded into the cache.
+var ErrWorkbookChangedOnDisk = errors.New("the workbook was changed on disk after it was cached, so its unsaved changes were not written over it")
+
+type cachedWorkbook struct {
+	file     *excelize.File
+	state    workbookFileState
+	refs     int
+	lastUsed time.Time
+	// unsaved is the content saved by a tool call which is not written to disk yet
+	unsaved []byte
+	// evicted workbooks are closed when the last reference is released
+	evicted bool
+}
+
+type workbookFileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+var (
+	currentCacheMu sync.Mutex
+	currentCache   *workbookCache
+)
+
+// EnableWorkbookCache makes OpenFile keep workbooks opened with excelize in memory.
+// Workbooks not used for idleTimeout are written to disk and closed. The unsaved changes
+// written outside of a tool call (after the idle timeout or on CloseWorkbookCache) are written
+// while holding the lock acquired by lockWorkbook, if it is not nil.
+func EnableWorkbookCache(idleTimeout time.Duration, lockWorkbook WorkbookLocker) {
+	currentCacheMu.Lock()
+	defer currentCacheMu.Unlock()
+	if currentCache != nil {
+		return
+	}
+	currentCache = newWorkbookCache(idleTimeout)
+	currentCache.lockWorkbook = lockWorkbook
+	go currentCache.evictIdleWorkbooks()
+}
+
+// CloseWorkbookCache writes the unsaved changes, closes all cached workbooks and disables the cache.
+func CloseWorkbookCache() error {
+	currentCacheMu.Lock()
+	cache := currentCache
+	currentCache = nil
+	currentCacheMu.Unlock()
+	if cache == nil {
+		return nil
+	}
+	close(cache.stop)
+	return cache.evictAll()
+}
+
+// FlushCachedWorkbook writes the unsaved changes of the cached workbook to disk.
+// It returns false if the workbook has no unsaved changes.
+func FlushCachedWorkbook(absoluteFilePath string) (bool, error) {
+	cache := getWorkbookCache()
+	if cache == nil {
+		return false, nil
+	}
+	return cache.flush(absoluteFilePath)
+}
+
+// CloseCachedWorkbook writes the unsaved changes of the cached workbook to disk and closes it
+// so that the next call reads it from disk again. It returns false if the workbook is not cached.
+func CloseCachedWorkbook(absoluteFilePath string) (bool, error) {
+	cache := getWorkbookCache()
+	if cache == nil {
+		return false, nil
+	}
+	return cache.evict(absoluteFilePath)
+}
+
+// DiscardCachedWorkbook closes the cached workbook without writing its unsaved changes.
+// It is used when the file on disk is replaced by other means (e.g. restoring a snapshot),
+// or to give up the changes which conflict with the file on disk. It returns false if the workbook is not cached.
+func DiscardCachedWorkbook(absoluteFilePath string) bool {
+	cache := getWorkbookCache()
+	if cache == nil {
+		return false
+	}
+	return cache.discard(absoluteFilePath)
+}
+
+// UnsavedWorkbookContent returns the content of the cached workbook which is saved but not written to disk yet.
+// It returns false if the workbook has no unsaved changes.
+func UnsavedWorkbookContent(absoluteFilePath string) ([]byte, bool) {
+	cache := getWorkbookCache()
+	if cache == nil {
+		return nil, false
+	}
+	return cache.unsavedContent(absoluteFilePath)
+}
+
+// IsWorkbookCacheEnabled reports whether EnableWorkbookCache has been called.
+func IsWorkbookCacheEnabled() bool {
+	return getWorkbookCache() != nil
+}
+
+func getWorkbookCache() *workbookCache {
+	currentCacheMu.Lock()
+	defer currentCacheMu.Unlock()
+	return currentCache
+}
+
+func newWorkbookCache(idleTimeout time.Duration) *workbookCache {
+	return &workbookCache{
+		idleTimeout: idleTimeout,
+		entries:     make(map[string]*cachedWorkbook),
+		stop:        make(chan struct{}),
+	}
+}
+
+func (c *workbookCache) open(absoluteFilePath string) (Excel, func(), error) {
+	path := filepath.Clean(absoluteFilePath)
+	state := statWorkbookFile(path)
+
+	entry := c.acquire(path, state, nil)
+	if entry == nil {
+		// Parse outside of the lock so that other workbooks are not blocked
+		file, err := openExcelizeFile(path)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		entry = c.acquire(path, state, file)
+	}
+
+	workbook := &ExcelizeExcel{file: entry.file}
+	workbook.saveLater = func() error {
+		return c.saveLater(path, entry)
+	}
+	return workbook, func() {
+		c.release(path, entry, workbook.IsModified())
+	}, nil
+}
+
+// acquire returns the cached workbook for the path with a reference held.
+// If no usable workbook is cached, it caches the given file, or returns nil if file is nil.
+func (c *workbookCache) acquire(path string, state workbookFileState, file *excelize.File) *cachedWorkbook {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if ok && entry.unsaved == nil && entry.state != state {
+		// The file was changed by someone else
+		c.evictLocked(path, entry)
+		ok = false
+	}
+	if ok {
+		if file != nil {
+			// Another call has loaded the same workbook in the meantime
+			file.Close()
+		}
+	} else {
+		if file == nil {
+			return nil
+		}
+		entry = &cachedWorkbook{file: file, state: state}
+		c.entries[path] = entry
+	}
+	entry.refs++
+	entry.lastUsed = time.Now()
+	return entry
+}
+
+// saveLater keeps the current content of the workbook in memory until it is flushed.
+func (c *workbookCache) saveLater(path string, entry *cachedWorkbook) error {
+	var content bytes.Buffer
+	if err := entry.file.Write(&content); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.unsaved = content.Bytes()
+	if entry.evicted {
+		// Nothing would write the workbook later since it is no longer cached
+		return c.flushLocked(path, entry)
+	}
+	return nil
+}
+
+func (c *workbookCache) release(path string, entry *cachedWorkbook, modified bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.refs--
+	entry.lastUsed = time.Now()
+	if modified {
+		// Discard changes which were not saved (e.g. the tool call failed or was cancelled)
+		c.rollbackLocked(path, entry)
+	}
+	if entry.evicted && entry.refs == 0 {
+		entry.file.Close()
+	}
+}
+
+// rollbackLocked replaces the workbook having changes which are not saved with the last saved state.
+func (c *workbookCache) rollbackLocked(path string, entry *cachedWorkbook) {
+	if entry.unsaved == nil {
+		// The next call reads the file from disk again
+		c.evictLocked(path, entry)
+		return
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(entry.unsaved))
+	if err != nil {
+		// Keep the saved changes rather than losing them
+		c.flushLocked(path, entry)
+		c.evictLocked(path, entry)
+		return
+	}
+	file.Path = path
+	if current, ok := c.entries[path]; ok && current == entry {
+		c.entries[path] = &cachedWorkbook{file: file, state: entry.state, lastUsed: entry.lastUsed, unsaved: entry.unsaved}
+	}
+	entry.unsaved = nil
+	c.evictLocked(path, entry)
+}
+
+func (c *workbookCache) flush(absoluteFilePath string) (bool, error) {
+	path := filepath.Clean(absoluteFilePath)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || entry.unsaved == nil {
+		return false, nil
+	}
+	if err := c.flushLocked(path, entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// flushLocked writes the unsaved content of the workbook to disk,
+// unless the file was changed on disk since the workbook was loaded or written.
+func (c *workbookCache) flushLocked(path string, entry *cachedWorkbook) error {
+	if entry.unsaved == nil {
+		return nil
+	}
+	if statWorkbookFile(path) != entry.state {
+		return fmt.Errorf("%w: %s", ErrWorkbookChangedOnDisk, path)
+	}
+	content := entry.unsaved
+	err := WriteFileAtomically(path, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	entry.unsaved = nil
+	entry.state = statWorkbookFile(path)
+	return nil
+}
+
+func (c *workbookCache) unsavedContent(absoluteFilePath string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[filepath.Clean(absoluteFilePath)]
+	if !ok || entry.unsaved == nil {
+		return nil, false
+	}
+	return entry.unsaved, true
+}
+
+func (c *workbookCache) evict(absoluteFilePath string) (bool, error) {
+	path := filepath.Clean(absoluteFilePath)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok {
+		return false, nil
+	}
+	if err := c.flushLocked(path, entry); err != nil {
+		return false, err
+	}
+	c.evictLocked(path, entry)
+	return true, nil
+}
+
+func (c *workbookCache) discard(absoluteFilePath string) bool {
+	path := filepath.Clean(absoluteFilePath)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok {
+		return false
+	}
+	entry.unsaved = nil
+	c.evictLocked(path, entry)
+	return true
+}
+
+func (c *workbookCache) evictAll() error {
+	c.mu.Lock()
+	paths := make([]string, 0, len(c.entries))
+	for path := range c.entries {
+		paths = append(paths, path)
+	}
+	c.mu.Unlock()
+	var errs []error
+	for _, path := range paths {
+		errs = append(errs, c.writeBack(path, func(entry *cachedWorkbook) bool { return true }))
+	}
+	return errors.Join(errs...)
+}
+
+// writeBack writes the unsaved changes of the workbook outside of a tool call and evicts it,
+// if it is still cached and evictable. The file is written while holding the workbook lock
+// so that it does not interleave with tool calls on the same workbook in this or another process.
+func (c *workbookCache) writeBack(path string, evictable func(entry *cachedWorkbook) bool) error {
+	if c.lockWorkbook != nil {
+		// Acquired before the cache lock, in the same order as tool calls
+		unlock, err := c.lockWorkbook(path)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || !evictable(entry) {
+		return nil
+	}
+	if err := c.flushLocked(path, entry); err != nil {
+		return err
+	}
+	c.evictLocked(path, entry)
+	return nil
+}
+
+// evictLocked removes the workbook from the cache. Its unsaved changes must be written or discarded beforehand.
+func (c *workbookCache) evictLocked(path string, entry *cachedWorkbook) {
+	if current, ok := c.entries[path]; ok && current == entry {
+		delete(c.entries, path)
+	}
+	if entry.evicted {
+		return
+	}
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.file.Close()
+	}
+}
+
+func (c *workbookCache) evictIdleWorkbooks() {
+	interval := c.idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			idle := func(entry *cachedWorkbook) bool {
+				return entry.refs == 0 && now.Sub(entry.lastUsed) >= c.idleTimeout
+			}
+			var unsavedPaths []string
+			c.mu.Lock()
+			for path, entry := range c.entries {
+				if !idle(entry) {
+					continue
+				}
+				if entry.unsaved != nil {
+					unsavedPaths = append(unsavedPaths, path)
+				} else {
+					c.evictLocked(path, entry)
+				}
+			}
+			c.mu.Unlock()
+			for _, path := range unsavedPaths {
+				// On failure (e.g. the file was changed on disk), the unsaved changes are kept
+				// so that excel_flush_cached_workbook reports the error
+				c.writeBack(path, idle)
+			}
+		}
+	}
+}
+
+func statWorkbookFile(path string) workbookFileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return workbookFileState{}
+	}
+	return workbookFileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+}
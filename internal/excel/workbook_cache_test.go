@@ -0,0 +1,353 @@
+package excel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func createTestWorkbook(t *testing.T, value string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	file := excelize.NewFile()
+	defer file.Close()
+	if err := file.SetCellValue("Sheet1", "A1", value); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func openCached(t *testing.T, cache *workbookCache, path string) (*ExcelizeExcel, func()) {
+	t.Helper()
+	workbook, release, err := cache.open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return workbook.(*ExcelizeExcel), release
+}
+
+func getA1(t *testing.T, workbook *ExcelizeExcel) string {
+	t.Helper()
+	worksheet, err := workbook.FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := worksheet.GetValue("A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestWorkbookCacheReusesUnchangedWorkbook(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	first, release := openCached(t, cache, path)
+	release()
+	second, release := openCached(t, cache, path)
+	defer release()
+
+	if first.file != second.file {
+		t.Error("expected the cached workbook to be reused")
+	}
+}
+
+func TestWorkbookCacheReloadsWorkbookChangedOnDisk(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	_, release := openCached(t, cache, path)
+	release()
+
+	// Overwrite the file from outside of the cache
+	other := excelize.NewFile()
+	other.SetCellValue("Sheet1", "A1", "changed outside")
+	other.SetCellValue("Sheet1", "B2", "to change the size")
+	if err := other.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+
+	workbook, release := openCached(t, cache, path)
+	defer release()
+	if got := getA1(t, workbook); got != "changed outside" {
+		t.Errorf("A1 = %q, want %q", got, "changed outside")
+	}
+}
+
+func TestWorkbookCacheKeepsSavedChanges(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	if err := worksheet.SetValue("A1", "saved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	reopened, release := openCached(t, cache, path)
+	defer release()
+	if reopened.file != workbook.file {
+		t.Error("expected the workbook saved by the cache to be reused")
+	}
+	if got := getA1(t, reopened); got != "saved" {
+		t.Errorf("A1 = %q, want %q", got, "saved")
+	}
+}
+
+func getA1OnDisk(t *testing.T, path string) string {
+	t.Helper()
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	value, err := file.GetCellValue("Sheet1", "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestWorkbookCacheDefersSaveUntilFlush(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	if err := worksheet.SetValue("A1", "saved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	if got := getA1OnDisk(t, path); got != "original" {
+		t.Errorf("A1 on disk before flush = %q, want %q", got, "original")
+	}
+	if _, ok := cache.unsavedContent(path); !ok {
+		t.Error("expected the saved content to be kept in the cache")
+	}
+	flushed, err := cache.flush(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flushed {
+		t.Error("expected the unsaved changes to be flushed")
+	}
+	if got := getA1OnDisk(t, path); got != "saved" {
+		t.Errorf("A1 on disk after flush = %q, want %q", got, "saved")
+	}
+
+	// The workbook written by the flush is still reused
+	reopened, release := openCached(t, cache, path)
+	defer release()
+	if reopened.file != workbook.file {
+		t.Error("expected the flushed workbook to be reused")
+	}
+}
+
+func TestWorkbookCacheWritesUnsavedChangesOnEviction(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "saved")
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	evicted, err := cache.evict(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !evicted {
+		t.Error("expected the workbook to be evicted")
+	}
+	if got := getA1OnDisk(t, path); got != "saved" {
+		t.Errorf("A1 on disk = %q, want %q", got, "saved")
+	}
+}
+
+func TestWorkbookCacheKeepsSavedChangesOfFailedCall(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "saved")
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// A call which fails after changing the workbook is rolled back to the last save
+	failed, release := openCached(t, cache, path)
+	worksheet, _ = failed.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "not saved")
+	release()
+
+	reopened, release := openCached(t, cache, path)
+	defer release()
+	if got := getA1(t, reopened); got != "saved" {
+		t.Errorf("A1 = %q, want %q", got, "saved")
+	}
+	if got := getA1OnDisk(t, path); got != "original" {
+		t.Errorf("A1 on disk = %q, want %q", got, "original")
+	}
+}
+
+func TestWorkbookCacheDiscard(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "saved")
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	cache.discard(path)
+	reopened, release := openCached(t, cache, path)
+	defer release()
+	if got := getA1(t, reopened); got != "original" {
+		t.Errorf("A1 = %q, want %q", got, "original")
+	}
+}
+
+func TestWorkbookCacheDiscardsUnsavedChanges(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	if err := worksheet.SetValue("A1", "unsaved"); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	reopened, release := openCached(t, cache, path)
+	defer release()
+	if got := getA1(t, reopened); got != "original" {
+		t.Errorf("A1 = %q, want %q", got, "original")
+	}
+}
+
+func TestWorkbookCacheEvictsIdleWorkbooks(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Second)
+	go cache.evictIdleWorkbooks()
+	defer close(cache.stop)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "saved")
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		cache.mu.Lock()
+		_, cached := cache.entries[filepath.Clean(path)]
+		cache.mu.Unlock()
+		if !cached {
+			if got := getA1OnDisk(t, path); got != "saved" {
+				t.Errorf("A1 on disk = %q, want %q", got, "saved")
+			}
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Error("expected the idle workbook to be evicted")
+}
+
+func TestWorkbookCacheCachesNewWorkbookAfterSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.xlsx")
+	cache := newWorkbookCache(time.Minute)
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "created")
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the workbook not to be written before flush: %v", err)
+	}
+	if _, err := cache.flush(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the workbook to be written: %v", err)
+	}
+	reopened, release := openCached(t, cache, path)
+	defer release()
+	if reopened.file != workbook.file {
+		t.Error("expected the flushed new workbook to be reused")
+	}
+}
+
+func TestWorkbookCacheDoesNotOverwriteFileChangedOnDisk(t *testing.T) {
+	path := createTestWorkbook(t, "original")
+	cache := newWorkbookCache(time.Minute)
+	var locked []string
+	cache.lockWorkbook = func(path string) (func(), error) {
+		locked = append(locked, path)
+		return func() {}, nil
+	}
+
+	workbook, release := openCached(t, cache, path)
+	worksheet, _ := workbook.FindSheet("Sheet1")
+	worksheet.SetValue("A1", "saved")
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// Another program writes the file while the save is deferred
+	other := excelize.NewFile()
+	other.SetCellValue("Sheet1", "A1", "changed outside")
+	other.SetCellValue("Sheet1", "B2", "to change the size")
+	if err := other.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+
+	if _, err := cache.flush(path); !errors.Is(err, ErrWorkbookChangedOnDisk) {
+		t.Errorf("flush error = %v, want %v", err, ErrWorkbookChangedOnDisk)
+	}
+	if err := cache.evictAll(); !errors.Is(err, ErrWorkbookChangedOnDisk) {
+		t.Errorf("evictAll error = %v, want %v", err, ErrWorkbookChangedOnDisk)
+	}
+	if !slices.Equal(locked, []string{filepath.Clean(path)}) {
+		t.Errorf("locked = %v, want the workbook to be locked while written on eviction", locked)
+	}
+	if got := getA1OnDisk(t, path); got != "changed outside" {
+		t.Errorf("A1 on disk = %q, want %q", got, "changed outside")
+	}
+	if _, ok := cache.unsavedContent(path); !ok {
+		t.Error("expected the unsaved changes to be kept")
+	}
+}
@@ -1,9 +1,13 @@
 package mcp
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	z "github.com/Oudwins/zog"
+	"github.com/Oudwins/zog/zconst"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -26,3 +30,19 @@ func NewToolResultZogIssueMap(errs z.ZogIssueMap) *mcp.CallToolResult {
 		IsError: true,
 	}
 }
+
+func NewZogIssueMapError(errs z.ZogIssueMap) error {
+	issues := z.Issues.SanitizeMap(errs)
+
+	var messages []string
+	for k, issueMessages := range issues {
+		if k == zconst.ISSUE_KEY_FIRST {
+			continue
+		}
+		for _, message := range issueMessages {
+			messages = append(messages, fmt.Sprintf("Invalid argument: %s: %s", k, message))
+		}
+	}
+	sort.Strings(messages)
+	return errors.New(strings.Join(messages, "\n"))
+}
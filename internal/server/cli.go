@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/negokaz/excel-mcp-server/internal/excel"
+)
+
+// ListTools returns the tools served with the configuration, sorted by name.
+func (s *ExcelServer) ListTools() []mcp.Tool {
+	var tools []mcp.Tool
+	for _, tool := range s.server.ListTools() {
+		tools = append(tools, tool.Tool)
+	}
+	slices.SortFunc(tools, func(a, b mcp.Tool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tools
+}
+
+// CallTool calls a tool without a client. The request goes through the MCP server in the same way as
+// a tools/call request, so that the configuration, locks and snapshots apply as well.
+func (s *ExcelServer) CallTool(ctx context.Context, name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	if s.server.GetTool(name) == nil {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+	message, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(1),
+		Request: mcp.Request{Method: string(mcp.MethodToolsCall)},
+		Params: mcp.CallToolParams{
+			Name:      name,
+			Arguments: arguments,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch response := s.server.HandleMessage(ctx, message).(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := response.Result.(*mcp.CallToolResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result of %s: %T", name, response.Result)
+		}
+		return result, nil
+	case mcp.JSONRPCError:
+		return nil, errors.New(response.Error.Message)
+	default:
+		return nil, fmt.Errorf("unexpected response of %s: %T", name, response)
+	}
+}
+
+// Close releases the resources held by the server.
+// It writes the unsaved changes in the workbook cache to disk.
+func (s *ExcelServer) Close() error {
+	return excel.CloseWorkbookCache()
+}
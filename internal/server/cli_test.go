@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/negokaz/excel-mcp-server/internal/tools"
+	"github.com/xuri/excelize/v2"
+)
+
+func newTestServer(t *testing.T) *ExcelServer {
+	t.Setenv("EXCEL_MCP_SNAPSHOT_DIR", t.TempDir())
+	config, issues := tools.LoadConfig()
+	if issues != nil {
+		t.Fatal(issues)
+	}
+	s, err := New("test", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return s
+}
+
+func TestToolsDeclareOutputSchema(t *testing.T) {
+	s := newTestServer(t)
+	for _, tool := range s.ListTools() {
+		if tool.OutputSchema.Type != "object" || len(tool.OutputSchema.Properties) == 0 {
+			t.Errorf("%s has no output schema: %+v", tool.Name, tool.OutputSchema)
+		}
+	}
+}
+
+func TestCallToolReturnsStructuredContent(t *testing.T) {
+	s := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	file := excelize.NewFile()
+	file.SetCellValue("Sheet1", "A1", "name")
+	file.SetCellValue("Sheet1", "A2", "apple")
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// The server validates the structured content against the output schema of each tool
+	calls := []struct {
+		tool      string
+		arguments map[string]any
+	}{
+		{"excel_describe_sheets", map[string]any{"fileAbsolutePath": path}},
+		{"excel_read_sheet", map[string]any{"fileAbsolutePath": path, "sheetName": "Sheet1"}},
+		{"excel_read_ranges", map[string]any{"fileAbsolutePath": path, "targets": []any{map[string]any{"range": "Sheet1!A1:A2"}}}},
+		{"excel_write_to_sheet", map[string]any{"fileAbsolutePath": path, "sheetName": "Sheet1", "newSheet": false, "range": "B1:B2", "values": []any{[]any{"price"}, []any{100}}}},
+		{"excel_find_replace", map[string]any{"fileAbsolutePath": path, "sheetName": "Sheet1", "find": "apple", "replace": "banana", "dryRun": true}},
+		{"excel_get_comments", map[string]any{"fileAbsolutePath": path, "sheetName": "Sheet1"}},
+		{"excel_list_snapshots", map[string]any{"fileAbsolutePath": path}},
+		{"excel_undo", map[string]any{"fileAbsolutePath": path}},
+	}
+	for _, call := range calls {
+		result, err := s.CallTool(context.Background(), call.tool, call.arguments)
+		if err != nil {
+			t.Fatalf("%s: %v", call.tool, err)
+		}
+		if result.IsError {
+			t.Fatalf("%s returned an error: %+v", call.tool, result.Content)
+		}
+		if result.StructuredContent == nil {
+			t.Errorf("%s returned no structured content", call.tool)
+		}
+		if len(result.Content) == 0 {
+			t.Errorf("%s returned no text fallback", call.tool)
+		}
+	}
+
+	result, err := s.CallTool(context.Background(), "excel_write_to_sheet", map[string]any{
+		"fileAbsolutePath": path, "sheetName": "Sheet1", "newSheet": false, "range": "C1", "values": []any{[]any{"note"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, ok := result.StructuredContent.(*tools.ExcelWriteToSheetOutput)
+	if !ok {
+		t.Fatalf("unexpected structured content: %T", result.StructuredContent)
+	}
+	if output.Range != "C1" || output.RowsWritten != 1 || output.SnapshotID == "" {
+		t.Errorf("unexpected structured content: %+v", output)
+	}
+	if _, ok := result.Content[0].(mcp.TextContent); !ok {
+		t.Errorf("unexpected fallback content: %T", result.Content[0])
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"runtime"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/negokaz/excel-mcp-server/internal/tools"
+)
+
+// toolRegistry adds tools to the MCP server, skipping the ones not permitted by the configuration.
+type toolRegistry struct {
+	server    *server.MCPServer
+	readOnly  bool
+	sandboxed bool
+	enabled   map[string]bool // nil means all tools are enabled
+	disabled  map[string]bool
+	known     map[string]bool
+	available bool
+}
+
+func newToolRegistry(s *server.MCPServer, config tools.EnvConfig) *toolRegistry {
+	var enabled map[string]bool
+	if names := config.EnabledTools(); len(names) > 0 {
+		enabled = toSet(names)
+	}
+	return &toolRegistry{
+		server:    s,
+		readOnly:  config.EXCEL_MCP_READ_ONLY,
+		sandboxed: len(config.AllowedRoots()) > 0,
+		enabled:   enabled,
+		disabled:  toSet(config.DisabledTools()),
+		known:     make(map[string]bool),
+		available: true,
+	}
+}
+
+// forPlatform returns a registry which only serves tools when running on the specified OS.
+// Tools added to it are still recognized as known tool names on the other platforms.
+func (r *toolRegistry) forPlatform(goos string) *toolRegistry {
+	platformRegistry := *r
+	platformRegistry.available = r.available && runtime.GOOS == goos
+	return &platformRegistry
+}
+
+func (r *toolRegistry) AddTool(access tools.ToolAccess, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	r.known[tool.Name] = true
+	if !r.available || !r.isAllowed(access, tool.Name) {
+		return
+	}
+	r.server.AddTool(tool, tools.WithProgress(tools.WithWorkbookLock(access, tools.WithSnapshot(tool.Name, access, handler))))
+}
+
+func (r *toolRegistry) isAllowed(access tools.ToolAccess, name string) bool {
+	if r.enabled != nil && !r.enabled[name] {
+		return false
+	}
+	if r.disabled[name] {
+		return false
+	}
+	if !r.readOnly {
+		return true
+	}
+	switch access {
+	case tools.ToolAccessRead:
+		return true
+	case tools.ToolAccessExport:
+		// Export tools write files, so they are only served when the output path is confined to the allowed roots
+		return r.sandboxed
+	default:
+		return false
+	}
+}
+
+// unknownToolNames returns the tool names in the configuration which do not match any tool.
+func (r *toolRegistry) unknownToolNames() []string {
+	var unknown []string
+	for name := range r.enabled {
+		if !r.known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range r.disabled {
+		if !r.known[name] && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
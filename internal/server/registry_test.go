@@ -0,0 +1,122 @@
+package server
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/negokaz/excel-mcp-server/internal/tools"
+)
+
+func TestToolRegistryIsAllowedInReadOnlyMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		readOnly     bool
+		allowedRoots string
+		access       tools.ToolAccess
+		want         bool
+	}{
+		{"read tool", true, "", tools.ToolAccessRead, true},
+		{"write tool", true, "", tools.ToolAccessWrite, false},
+		{"export tool without allowed roots", true, "", tools.ToolAccessExport, false},
+		{"export tool with allowed roots", true, t.TempDir(), tools.ToolAccessExport, true},
+		{"write tool with allowed roots", true, t.TempDir(), tools.ToolAccessWrite, false},
+		{"write tool without read-only mode", false, "", tools.ToolAccessWrite, true},
+		{"export tool without read-only mode", false, "", tools.ToolAccessExport, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newToolRegistry(nil, tools.EnvConfig{EXCEL_MCP_READ_ONLY: tt.readOnly, EXCEL_MCP_ALLOWED_ROOTS: tt.allowedRoots})
+			if got := registry.isAllowed(tt.access, "excel_tool"); got != tt.want {
+				t.Errorf("isAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnlyServerHidesWriteTools(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedRoots string
+		want         map[string]bool
+	}{
+		{"without allowed roots", "", map[string]bool{"excel_read_sheet": true, "excel_write_to_sheet": false, "excel_export_csv": false}},
+		{"with allowed roots", t.TempDir(), map[string]bool{"excel_read_sheet": true, "excel_write_to_sheet": false, "excel_export_csv": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EXCEL_MCP_READ_ONLY", "true")
+			t.Setenv("EXCEL_MCP_ALLOWED_ROOTS", tt.allowedRoots)
+			s := newTestServer(t)
+			served := make(map[string]bool)
+			for _, tool := range s.ListTools() {
+				served[tool.Name] = true
+			}
+			for name, want := range tt.want {
+				if served[name] != want {
+					t.Errorf("%s is served: %v, want %v", name, served[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestToolRegistryIsAllowedByToolNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  string
+		disabled string
+		want     map[string]bool
+	}{
+		{"all tools by default", "", "", map[string]bool{"excel_read_sheet": true, "excel_write_to_sheet": true}},
+		{"enabled tools only", "excel_read_sheet", "", map[string]bool{"excel_read_sheet": true, "excel_write_to_sheet": false}},
+		{"disabled tools", "", "excel_write_to_sheet", map[string]bool{"excel_read_sheet": true, "excel_write_to_sheet": false}},
+		{"disabled takes precedence over enabled", "excel_read_sheet,excel_write_to_sheet", "excel_write_to_sheet", map[string]bool{"excel_read_sheet": true, "excel_write_to_sheet": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newToolRegistry(nil, tools.EnvConfig{EXCEL_MCP_ENABLED_TOOLS: tt.enabled, EXCEL_MCP_DISABLED_TOOLS: tt.disabled})
+			for name, want := range tt.want {
+				if got := registry.isAllowed(tools.ToolAccessWrite, name); got != want {
+					t.Errorf("isAllowed(%q) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestToolRegistryUnknownToolNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  string
+		disabled string
+		want     []string
+	}{
+		{"no names", "", "", nil},
+		{"known names", "excel_read_sheet", "excel_write_to_sheet", nil},
+		{"unknown enabled name", "excel_read_sheet,excel_read_shet", "", []string{"excel_read_shet"}},
+		{"unknown disabled name", "", "excel_run_macros", []string{"excel_run_macros"}},
+		{"unknown name in both lists is reported once", "excel_typo", "excel_typo,excel_other", []string{"excel_other", "excel_typo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newToolRegistry(nil, tools.EnvConfig{EXCEL_MCP_ENABLED_TOOLS: tt.enabled, EXCEL_MCP_DISABLED_TOOLS: tt.disabled})
+			registry.known["excel_read_sheet"] = true
+			registry.known["excel_write_to_sheet"] = true
+			if got := registry.unknownToolNames(); !slices.Equal(got, tt.want) {
+				t.Errorf("unknownToolNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewServerRejectsUnknownToolNames(t *testing.T) {
+	t.Setenv("EXCEL_MCP_DISABLED_TOOLS", "excel_no_such_tool")
+	config, issues := tools.LoadConfig()
+	if issues != nil {
+		t.Fatal(issues)
+	}
+	if _, err := New("test", config); err == nil || !strings.Contains(err.Error(), "excel_no_such_tool") {
+		t.Errorf("New() error = %v, want the unknown tool name to be reported", err)
+	}
+}
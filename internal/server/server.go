@@ -1,66 +1,114 @@
 package server
 
 import (
-	"runtime"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/negokaz/excel-mcp-server/internal/excel"
 	"github.com/negokaz/excel-mcp-server/internal/tools"
 )
 
+// resourceWatchInterval is the interval for checking whether subscribed workbooks have changed
+const resourceWatchInterval = 2 * time.Second
+
 type ExcelServer struct {
-	server *server.MCPServer
+	server  *server.MCPServer
+	watcher *tools.ResourceWatcher
 }
 
-func New(version string) *ExcelServer {
+func New(version string, config tools.EnvConfig) (*ExcelServer, error) {
+	excel.SetBackupGenerations(config.EXCEL_MCP_BACKUP_GENERATIONS)
+
 	s := &ExcelServer{}
+	s.watcher = tools.NewResourceWatcher(resourceWatchInterval)
+	hooks := &server.Hooks{}
+	s.watcher.RegisterHooks(hooks)
 	s.server = server.NewMCPServer(
 		"excel-mcp-server",
 		version,
+		server.WithHooks(hooks),
+		server.WithResourceCapabilities(true, false),
+		// The output schemas are generated from the structured content types, so a mismatch is a bug
+		server.WithOutputSchemaValidation(),
 	)
-	tools.AddExcelDescribeSheetsTool(s.server)
-	tools.AddExcelReadSheetTool(s.server)
-	if runtime.GOOS == "windows" {
-		tools.AddExcelScreenCaptureTool(s.server)
-	}
-	tools.AddExcelWriteToSheetTool(s.server)
-	tools.AddExcelCreateTableTool(s.server)
-	tools.AddExcelCopySheetTool(s.server)
-	tools.AddExcelFormatRangeTool(s.server)
-	tools.AddExcelDeleteSheetTool(s.server)
-	tools.AddExcelRenameSheetTool(s.server)
-	tools.AddExcelMergeCellsTool(s.server)
-	tools.AddExcelUnmergeCellsTool(s.server)
-	tools.AddExcelSetColumnWidthTool(s.server)
-	tools.AddExcelSetRowHeightTool(s.server)
-	tools.AddExcelInsertRowsTool(s.server)
-	tools.AddExcelDeleteRowsTool(s.server)
-	tools.AddExcelInsertColumnsTool(s.server)
-	tools.AddExcelDeleteColumnsTool(s.server)
-	tools.AddExcelAddChartTool(s.server)
-	tools.AddExcelFreezePanesTool(s.server)
-	tools.AddExcelAddDataValidationTool(s.server)
-	tools.AddExcelFindReplaceTool(s.server)
+	registry := newToolRegistry(s.server, config)
+	tools.AddExcelDescribeSheetsTool(registry)
+	tools.AddExcelReadSheetTool(registry)
+	tools.AddExcelReadRangesTool(registry)
+	tools.AddExcelScreenCaptureTool(registry.forPlatform("windows"))
+	tools.AddExcelWriteToSheetTool(registry)
+	tools.AddExcelCreateTableTool(registry)
+	tools.AddExcelCopySheetTool(registry)
+	tools.AddExcelFormatRangeTool(registry)
+	tools.AddExcelDeleteSheetTool(registry)
+	tools.AddExcelRenameSheetTool(registry)
+	tools.AddExcelMergeCellsTool(registry)
+	tools.AddExcelUnmergeCellsTool(registry)
+	tools.AddExcelSetColumnWidthTool(registry)
+	tools.AddExcelSetRowHeightTool(registry)
+	tools.AddExcelInsertRowsTool(registry)
+	tools.AddExcelDeleteRowsTool(registry)
+	tools.AddExcelInsertColumnsTool(registry)
+	tools.AddExcelDeleteColumnsTool(registry)
+	tools.AddExcelAddChartTool(registry)
+	tools.AddExcelFreezePanesTool(registry)
+	tools.AddExcelAddDataValidationTool(registry)
+	tools.AddExcelFindReplaceTool(registry)
 	// Phase 1: Formula Engine tools
-	tools.AddExcelAddCommentTool(s.server)
-	tools.AddExcelGetCommentsTool(s.server)
-	tools.AddExcelAddHyperlinkTool(s.server)
-	tools.AddExcelSetNamedRangeTool(s.server)
-	tools.AddExcelSetConditionalFormatTool(s.server)
+	tools.AddExcelAddCommentTool(registry)
+	tools.AddExcelGetCommentsTool(registry)
+	tools.AddExcelAddHyperlinkTool(registry)
+	tools.AddExcelSetNamedRangeTool(registry)
+	tools.AddExcelSetConditionalFormatTool(registry)
+	tools.AddExcelBatchTool(registry)
 	// Phase 2: Live Excel Control tools (Windows only)
-	if runtime.GOOS == "windows" {
-		tools.AddExcelListWorkbooksTool(s.server)
-		tools.AddExcelOpenWorkbookTool(s.server)
-		tools.AddExcelCreateWorkbookTool(s.server)
-		tools.AddExcelRunMacroTool(s.server)
-	}
+	windowsRegistry := registry.forPlatform("windows")
+	tools.AddExcelListWorkbooksTool(windowsRegistry)
+	tools.AddExcelOpenWorkbookTool(windowsRegistry)
+	tools.AddExcelCreateWorkbookTool(windowsRegistry)
+	tools.AddExcelRunMacroTool(windowsRegistry)
 	// Phase 3: Import/Export tools
-	tools.AddExcelExportCsvTool(s.server)
-	tools.AddExcelImportCsvTool(s.server)
-	tools.AddExcelExportJsonTool(s.server)
-	tools.AddExcelImportJsonTool(s.server)
-	return s
+	tools.AddExcelExportCsvTool(registry)
+	tools.AddExcelImportCsvTool(registry)
+	tools.AddExcelExportJsonTool(registry)
+	tools.AddExcelImportJsonTool(registry)
+	// Workbook cache
+	if config.EXCEL_MCP_WORKBOOK_CACHE {
+		excel.EnableWorkbookCache(time.Duration(config.EXCEL_MCP_WORKBOOK_CACHE_IDLE_TIMEOUT)*time.Second, tools.LockWorkbookForWriting)
+	}
+	tools.AddExcelFlushCachedWorkbookTool(registry)
+	tools.AddExcelCloseCachedWorkbookTool(registry)
+	// Snapshots
+	tools.AddExcelListSnapshotsTool(registry)
+	tools.AddExcelUndoTool(registry)
+	tools.AddExcelRestoreSnapshotTool(registry)
+	// Resources
+	tools.AddExcelResourceTemplates(s.server)
+	// Prompts
+	tools.AddExcelPrompts(s.server)
+
+	if unknown := registry.unknownToolNames(); len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown tool names in configuration: %s", strings.Join(unknown, ", "))
+	}
+	return s, nil
 }
 
-func (s *ExcelServer) Start() error {
-	return server.ServeStdio(s.server)
+func (s *ExcelServer) Start(options TransportOptions) (err error) {
+	s.watcher.Start(s.server)
+	defer s.watcher.Stop()
+	defer func() {
+		// Unsaved changes in the workbook cache are written on shutdown
+		err = errors.Join(err, s.Close())
+	}()
+	switch options.Transport {
+	case "", TransportStdio:
+		return server.ServeStdio(s.server)
+	case TransportStreamableHTTP, TransportSSE:
+		return s.serveHTTP(options)
+	default:
+		return fmt.Errorf("unsupported transport: %s", options.Transport)
+	}
 }
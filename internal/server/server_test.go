@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xuri/excelize/v2"
+)
+
+// handleTestMessage sends a JSON-RPC request to the server and returns the response.
+func handleTestMessage(t *testing.T, s *ExcelServer, method string, params map[string]any) mcp.JSONRPCMessage {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.server.HandleMessage(context.Background(), message)
+}
+
+func TestResourceTemplatesFollowReadTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	file := excelize.NewFile()
+	file.SetCellValue("Sheet1", "A1", "secret")
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	workbookURI := "excel://" + path
+	rangeURI := "excel://" + path + "/Sheet1/A1:A1"
+	tests := []struct {
+		name          string
+		disabledTools string
+		uri           string
+		wantTemplates []string
+		wantErr       bool
+	}{
+		{"all tools", "", rangeURI, []string{"excel://{+path}", "excel://{+path}/{sheet}/{range}"}, false},
+		{"range without read sheet", "excel_read_sheet", rangeURI, []string{"excel://{+path}"}, true},
+		{"workbook without read sheet", "excel_read_sheet", workbookURI, []string{"excel://{+path}"}, false},
+		{"workbook without describe sheets", "excel_describe_sheets", workbookURI, []string{"excel://{+path}/{sheet}/{range}"}, true},
+		{"no read tools", "excel_describe_sheets,excel_read_sheet", workbookURI, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EXCEL_MCP_DISABLED_TOOLS", tt.disabledTools)
+			s := newTestServer(t)
+
+			response, ok := handleTestMessage(t, s, string(mcp.MethodResourcesTemplatesList), map[string]any{}).(mcp.JSONRPCResponse)
+			if !ok {
+				t.Fatalf("failed to list the resource templates: %+v", response)
+			}
+			var templates []string
+			for _, template := range response.Result.(mcp.ListResourceTemplatesResult).ResourceTemplates {
+				templates = append(templates, template.URITemplate.Raw())
+			}
+			slices.Sort(templates)
+			if !slices.Equal(templates, tt.wantTemplates) {
+				t.Errorf("templates = %v, want %v", templates, tt.wantTemplates)
+			}
+
+			_, isErr := handleTestMessage(t, s, string(mcp.MethodResourcesRead), map[string]any{"uri": tt.uri}).(mcp.JSONRPCError)
+			if isErr != tt.wantErr {
+				t.Errorf("reading %s returned an error: %v, want %v", tt.uri, isErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	TransportStdio          = "stdio"
+	TransportStreamableHTTP = "streamable-http"
+	TransportSSE            = "sse"
+)
+
+// TransportOptions configures how the server is exposed to MCP clients.
+type TransportOptions struct {
+	// Transport is one of TransportStdio, TransportStreamableHTTP or TransportSSE.
+	Transport string
+	// Addr is the TCP address the HTTP transports listen on (e.g. "127.0.0.1:8000").
+	Addr string
+	// BasePath is the URL path under which the HTTP endpoints are mounted (e.g. "/mcp").
+	BasePath string
+	// AuthToken enables bearer token authentication for the HTTP transports when not empty.
+	AuthToken string
+}
+
+func (s *ExcelServer) serveHTTP(options TransportOptions) error {
+	basePath := "/" + strings.Trim(options.BasePath, "/")
+	handler, shutdown, err := s.newHTTPHandler(options.Transport, basePath, options.AuthToken)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Addr:              options.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "excel-mcp-server listening on %s%s (%s)\n", options.Addr, basePath, options.Transport)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// Close MCP sessions first so that long-lived SSE streams do not block the HTTP shutdown
+		if err := shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// newHTTPHandler returns the handler serving the HTTP transport under the base path,
+// and the function to close its MCP sessions.
+func (s *ExcelServer) newHTTPHandler(transport string, basePath string, authToken string) (http.Handler, func(ctx context.Context) error, error) {
+	var handler http.Handler
+	var shutdown func(ctx context.Context) error
+	switch transport {
+	case TransportStreamableHTTP:
+		streamableServer := server.NewStreamableHTTPServer(s.server,
+			server.WithEndpointPath(basePath),
+		)
+		mux := http.NewServeMux()
+		mux.Handle(basePath, streamableServer)
+		handler = mux
+		shutdown = streamableServer.Shutdown
+	case TransportSSE:
+		sseServer := server.NewSSEServer(s.server,
+			server.WithStaticBasePath(basePath),
+		)
+		handler = sseServer
+		shutdown = sseServer.Shutdown
+	default:
+		return nil, nil, fmt.Errorf("unsupported HTTP transport: %s", transport)
+	}
+
+	if authToken != "" {
+		handler = withBearerAuth(authToken, handler)
+	}
+	return handler, shutdown, nil
+}
+
+// withBearerAuth rejects requests which do not carry "Authorization: Bearer <token>".
+func withBearerAuth(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actual := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(actual, expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="excel-mcp-server"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithBearerAuth(t *testing.T) {
+	handler := withBearerAuth("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", http.StatusUnauthorized},
+		{"token without scheme", "secret", http.StatusUnauthorized},
+		{"correct token", "Bearer secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected the WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerRoutesBasePath(t *testing.T) {
+	s := newTestServer(t)
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"0.0.0"}}}`
+
+	tests := []struct {
+		name      string
+		transport string
+		path      string
+		token     string
+		want      int
+	}{
+		{"streamable http at the base path", TransportStreamableHTTP, "/mcp", "Bearer secret", http.StatusOK},
+		{"streamable http outside of the base path", TransportStreamableHTTP, "/other", "Bearer secret", http.StatusNotFound},
+		{"streamable http without token", TransportStreamableHTTP, "/mcp", "", http.StatusUnauthorized},
+		{"streamable http with wrong token", TransportStreamableHTTP, "/mcp", "Bearer wrong", http.StatusUnauthorized},
+		// The message endpoint requires the session of the SSE stream
+		{"sse message endpoint under the base path", TransportSSE, "/mcp/message", "Bearer secret", http.StatusBadRequest},
+		{"sse message endpoint outside of the base path", TransportSSE, "/message", "Bearer secret", http.StatusNotFound},
+		{"sse without token", TransportSSE, "/mcp/message", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, err := s.newHTTPHandler(tt.transport, "/mcp", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpServer := httptest.NewServer(handler)
+			defer httpServer.Close()
+
+			request, err := http.NewRequest(http.MethodPost, httpServer.URL+tt.path, strings.NewReader(initialize))
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Accept", "application/json, text/event-stream")
+			if tt.token != "" {
+				request.Header.Set("Authorization", tt.token)
+			}
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			if response.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerRejectsUnknownTransport(t *testing.T) {
+	s := newTestServer(t)
+	if _, _, err := s.newHTTPHandler(TransportStdio, "/mcp", ""); err == nil {
+		t.Error("expected an error for a transport which is not served over HTTP")
+	}
+}
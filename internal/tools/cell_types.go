@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+// cellTypeInfo is the type of a cell rendered along with its formatted text.
+type cellTypeInfo struct {
+	Type       excel.CellValueType `json:"type"`
+	ResultType excel.CellValueType `json:"resultType,omitempty"`
+	// RawValue is omitted if it is the same as the formatted text
+	RawValue string `json:"rawValue,omitempty"`
+	NumFmt   string `json:"numFmt,omitempty"`
+}
+
+func newCellTypeInfo(info *excel.CellInfo) *cellTypeInfo {
+	typeInfo := &cellTypeInfo{Type: info.Type, ResultType: info.ResultType, NumFmt: info.NumFmt}
+	if info.Value != info.Text {
+		typeInfo.RawValue = info.Value
+	}
+	return typeInfo
+}
+
+// htmlAttributes renders the type as the data attributes of a td element.
+func (i *cellTypeInfo) htmlAttributes() string {
+	if i == nil {
+		return ""
+	}
+	attributes := fmt.Sprintf(" data-type=\"%s\"", i.Type)
+	if i.ResultType != "" {
+		attributes += fmt.Sprintf(" data-result-type=\"%s\"", i.ResultType)
+	}
+	if i.RawValue != "" {
+		attributes += fmt.Sprintf(" data-raw-value=\"%s\"", html.EscapeString(i.RawValue))
+	}
+	if i.NumFmt != "" {
+		attributes += fmt.Sprintf(" data-num-fmt=\"%s\"", html.EscapeString(i.NumFmt))
+	}
+	return attributes
+}
+
+// readCellTypes reads the types of the non-empty cells of the table.
+func (t *tableData) readCellTypes(ctx context.Context, infoExtractor func(cell string) (*excel.CellInfo, error)) error {
+	t.types = make([][]*cellTypeInfo, len(t.values))
+	for i, values := range t.values {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t.types[i] = make([]*cellTypeInfo, len(values))
+		for j, value := range values {
+			if value == "" {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(t.startCol+j, t.startRow+i)
+			info, err := infoExtractor(cell)
+			if err != nil {
+				return err
+			}
+			t.types[i][j] = newCellTypeInfo(info)
+		}
+	}
+	return nil
+}
+
+// typedCell is a cell exported with its type.
+type typedCell struct {
+	// Value is the raw value as a JSON number, boolean or string
+	Value      any                 `json:"value"`
+	Type       excel.CellValueType `json:"type"`
+	ResultType excel.CellValueType `json:"resultType,omitempty"`
+	Formula    string              `json:"formula,omitempty"`
+	NumFmt     string              `json:"numFmt,omitempty"`
+	Text       string              `json:"text"`
+}
+
+func newTypedCell(info *excel.CellInfo) typedCell {
+	valueType := info.Type
+	if valueType == excel.CellValueTypeFormula {
+		valueType = info.ResultType
+	}
+	var value any = info.Value
+	switch valueType {
+	case excel.CellValueTypeNumber, excel.CellValueTypeDate:
+		if _, err := strconv.ParseFloat(info.Value, 64); err == nil {
+			value = json.Number(info.Value)
+		}
+	case excel.CellValueTypeBool:
+		value = info.Value == "TRUE"
+	case excel.CellValueTypeEmpty:
+		value = nil
+	}
+	return typedCell{
+		Value:      value,
+		Type:       info.Type,
+		ResultType: info.ResultType,
+		Formula:    info.Formula,
+		NumFmt:     info.NumFmt,
+		Text:       info.Text,
+	}
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+)
+
+func TestNewTypedCell(t *testing.T) {
+	tests := []struct {
+		info excel.CellInfo
+		want string
+	}{
+		{
+			excel.CellInfo{Value: "45296", Type: excel.CellValueTypeDate, NumFmt: "yyyy-mm-dd", Text: "2024-01-05"},
+			`{"value":45296,"type":"date","numFmt":"yyyy-mm-dd","text":"2024-01-05"}`,
+		},
+		{
+			excel.CellInfo{Value: "45296", Type: excel.CellValueTypeString, Text: "45296"},
+			`{"value":"45296","type":"string","text":"45296"}`,
+		},
+		{
+			excel.CellInfo{Value: "TRUE", Type: excel.CellValueTypeFormula, ResultType: excel.CellValueTypeBool, Formula: "=A1=1", Text: "TRUE"},
+			`{"value":true,"type":"formula","resultType":"bool","formula":"=A1=1","text":"TRUE"}`,
+		},
+		{
+			excel.CellInfo{Type: excel.CellValueTypeEmpty},
+			`{"value":null,"type":"empty","text":""}`,
+		},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(newTypedCell(&tt.info))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("newTypedCell(%+v) = %s, want %s", tt.info, data, tt.want)
+		}
+	}
+}
+
+func TestCellTypeInfoHTMLAttributes(t *testing.T) {
+	info := newCellTypeInfo(&excel.CellInfo{Value: "1234.5", Type: excel.CellValueTypeNumber, NumFmt: `#,##0.00" USD"`, Text: "1,234.50 USD"})
+	want := ` data-type="number" data-raw-value="1234.5" data-num-fmt="#,##0.00&#34; USD&#34;"`
+	if got := info.htmlAttributes(); got != want {
+		t.Errorf("htmlAttributes() = %s, want %s", got, want)
+	}
+	// The raw value is omitted if it is the same as the text
+	if got := newCellTypeInfo(&excel.CellInfo{Value: "abc", Type: excel.CellValueTypeString, Text: "abc"}).htmlAttributes(); got != ` data-type="string"` {
+		t.Errorf("htmlAttributes() = %s", got)
+	}
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"crypto/md5"
 	"fmt"
 	"html"
@@ -10,7 +11,6 @@ import (
 	"strings"
 
 	"github.com/goccy/go-yaml"
-	"github.com/xuri/excelize/v2"
 
 	"github.com/negokaz/excel-mcp-server/internal/excel"
 
@@ -33,6 +33,16 @@ type StyleRegistry struct {
 	fillHashToID map[string]string // styleHash -> styleID
 	fillCounter  int
 
+	// Alignment styles
+	alignmentStyles   map[string]string // styleID -> YAML string
+	alignmentHashToID map[string]string // styleHash -> styleID
+	alignmentCounter  int
+
+	// Protection styles
+	protectionStyles   map[string]string // styleID -> YAML string
+	protectionHashToID map[string]string // styleHash -> styleID
+	protectionCounter  int
+
 	// Number format styles
 	numFmtStyles   map[string]string // styleID -> NumFmt
 	numFmtHashToID map[string]string // styleHash -> styleID
@@ -46,21 +56,27 @@ type StyleRegistry struct {
 
 func NewStyleRegistry() *StyleRegistry {
 	return &StyleRegistry{
-		borderStyles:    make(map[string]string),
-		borderHashToID:  make(map[string]string),
-		borderCounter:   0,
-		fontStyles:      make(map[string]string),
-		fontHashToID:    make(map[string]string),
-		fontCounter:     0,
-		fillStyles:      make(map[string]string),
-		fillHashToID:    make(map[string]string),
-		fillCounter:     0,
-		numFmtStyles:    make(map[string]string),
-		numFmtHashToID:  make(map[string]string),
-		numFmtCounter:   0,
-		decimalStyles:   make(map[string]string),
-		decimalHashToID: make(map[string]string),
-		decimalCounter:  0,
+		borderStyles:       make(map[string]string),
+		borderHashToID:     make(map[string]string),
+		borderCounter:      0,
+		fontStyles:         make(map[string]string),
+		fontHashToID:       make(map[string]string),
+		fontCounter:        0,
+		fillStyles:         make(map[string]string),
+		fillHashToID:       make(map[string]string),
+		fillCounter:        0,
+		alignmentStyles:    make(map[string]string),
+		alignmentHashToID:  make(map[string]string),
+		alignmentCounter:   0,
+		protectionStyles:   make(map[string]string),
+		protectionHashToID: make(map[string]string),
+		protectionCounter:  0,
+		numFmtStyles:       make(map[string]string),
+		numFmtHashToID:     make(map[string]string),
+		numFmtCounter:      0,
+		decimalStyles:      make(map[string]string),
+		decimalHashToID:    make(map[string]string),
+		decimalCounter:     0,
 	}
 }
 
@@ -92,6 +108,20 @@ func (sr *StyleRegistry) RegisterStyle(cellStyle *excel.CellStyle) []string {
 		}
 	}
 
+	// Register alignment style
+	if cellStyle.Alignment != nil {
+		if alignmentID := sr.RegisterAlignmentStyle(cellStyle.Alignment); alignmentID != "" {
+			styleIDs = append(styleIDs, alignmentID)
+		}
+	}
+
+	// Register protection style
+	if cellStyle.Protection != nil {
+		if protectionID := sr.RegisterProtectionStyle(cellStyle.Protection); protectionID != "" {
+			styleIDs = append(styleIDs, protectionID)
+		}
+	}
+
 	// Register number format style
 	if cellStyle.NumFmt != nil && *cellStyle.NumFmt != "" {
 		if numFmtID := sr.RegisterNumFmtStyle(*cellStyle.NumFmt); numFmtID != "" {
@@ -110,7 +140,7 @@ func (sr *StyleRegistry) RegisterStyle(cellStyle *excel.CellStyle) []string {
 }
 
 func (sr *StyleRegistry) isEmptyStyle(style *excel.CellStyle) bool {
-	if len(style.Border) > 0 || style.Font != nil || (style.NumFmt != nil && *style.NumFmt != "") || (style.DecimalPlaces != nil && *style.DecimalPlaces != 0) {
+	if len(style.Border) > 0 || style.Font != nil || style.Alignment != nil || style.Protection != nil || (style.NumFmt != nil && *style.NumFmt != "") || (style.DecimalPlaces != nil && *style.DecimalPlaces != 0) {
 		return false
 	}
 	if style.Fill != nil && style.Fill.Type != "" {
@@ -210,6 +240,60 @@ func (sr *StyleRegistry) RegisterFillStyle(fill *excel.FillStyle) string {
 	return styleID
 }
 
+func (sr *StyleRegistry) RegisterAlignmentStyle(alignment *excel.AlignmentStyle) string {
+	if alignment == nil {
+		return ""
+	}
+
+	yamlStr := convertToYAMLFlow(alignment)
+	if yamlStr == "" {
+		return ""
+	}
+
+	styleHash := calculateYamlHash(yamlStr)
+	if styleHash == "" {
+		return ""
+	}
+
+	if existingID, exists := sr.alignmentHashToID[styleHash]; exists {
+		return existingID
+	}
+
+	sr.alignmentCounter++
+	styleID := fmt.Sprintf("a%d", sr.alignmentCounter)
+	sr.alignmentStyles[styleID] = yamlStr
+	sr.alignmentHashToID[styleHash] = styleID
+
+	return styleID
+}
+
+func (sr *StyleRegistry) RegisterProtectionStyle(protection *excel.ProtectionStyle) string {
+	if protection == nil {
+		return ""
+	}
+
+	yamlStr := convertToYAMLFlow(protection)
+	if yamlStr == "" {
+		return ""
+	}
+
+	styleHash := calculateYamlHash(yamlStr)
+	if styleHash == "" {
+		return ""
+	}
+
+	if existingID, exists := sr.protectionHashToID[styleHash]; exists {
+		return existingID
+	}
+
+	sr.protectionCounter++
+	styleID := fmt.Sprintf("p%d", sr.protectionCounter)
+	sr.protectionStyles[styleID] = yamlStr
+	sr.protectionHashToID[styleHash] = styleID
+
+	return styleID
+}
+
 func (sr *StyleRegistry) RegisterNumFmtStyle(numFmt string) string {
 	if numFmt == "" {
 		return ""
@@ -260,7 +344,7 @@ func (sr *StyleRegistry) RegisterDecimalStyle(decimal int) string {
 }
 
 func (sr *StyleRegistry) GenerateStyleDefinitions() string {
-	totalCount := len(sr.borderStyles) + len(sr.fontStyles) + len(sr.fillStyles) + len(sr.numFmtStyles) + len(sr.decimalStyles)
+	totalCount := len(sr.borderStyles) + len(sr.fontStyles) + len(sr.fillStyles) + len(sr.alignmentStyles) + len(sr.protectionStyles) + len(sr.numFmtStyles) + len(sr.decimalStyles)
 	if totalCount == 0 {
 		return ""
 	}
@@ -278,6 +362,12 @@ func (sr *StyleRegistry) GenerateStyleDefinitions() string {
 	// Generate fill style definitions
 	result.WriteString(sr.generateStyleDefTag(sr.fillStyles, "fill"))
 
+	// Generate alignment style definitions
+	result.WriteString(sr.generateStyleDefTag(sr.alignmentStyles, "alignment"))
+
+	// Generate protection style definitions
+	result.WriteString(sr.generateStyleDefTag(sr.protectionStyles, "protection"))
+
 	// Generate number format style definitions
 	result.WriteString(sr.generateStyleDefTag(sr.numFmtStyles, "numFmt"))
 
@@ -288,6 +378,27 @@ func (sr *StyleRegistry) GenerateStyleDefinitions() string {
 	return result.String()
 }
 
+// Definitions returns the registered styles keyed by style ID in the same "label: yaml" form as the HTML definitions.
+func (sr *StyleRegistry) Definitions() map[string]string {
+	definitions := make(map[string]string)
+	for label, styles := range map[string]map[string]string{
+		"border":        sr.borderStyles,
+		"font":          sr.fontStyles,
+		"fill":          sr.fillStyles,
+		"alignment":     sr.alignmentStyles,
+		"protection":    sr.protectionStyles,
+		"numFmt":        sr.numFmtStyles,
+		"decimalPlaces": sr.decimalStyles,
+	} {
+		for styleID, yamlStr := range styles {
+			if yamlStr != "" {
+				definitions[styleID] = fmt.Sprintf("%s: %s", label, yamlStr)
+			}
+		}
+	}
+	return definitions
+}
+
 func (sr *StyleRegistry) generateStyleDefTag(styles map[string]string, styleLabel string) string {
 	if len(styles) == 0 {
 		return ""
@@ -331,25 +442,25 @@ func convertToYAMLFlow(value any) string {
 	return yamlStr
 }
 
-func CreateHTMLTableOfValues(worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
-	return createHTMLTable(startCol, startRow, endCol, endRow, func(cellRange string) (string, error) {
+func CreateHTMLTableOfValues(ctx context.Context, worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
+	return createHTMLTable(ctx, startCol, startRow, endCol, endRow, func(cellRange string) (string, error) {
 		return worksheet.GetValue(cellRange)
 	})
 }
 
-func CreateHTMLTableOfFormula(worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
-	return createHTMLTable(startCol, startRow, endCol, endRow, func(cellRange string) (string, error) {
+func CreateHTMLTableOfFormula(ctx context.Context, worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
+	return createHTMLTable(ctx, startCol, startRow, endCol, endRow, func(cellRange string) (string, error) {
 		return worksheet.GetFormula(cellRange)
 	})
 }
 
 // CreateHTMLTable creates a table data in HTML format
-func createHTMLTable(startCol int, startRow int, endCol int, endRow int, extractor func(cellRange string) (string, error)) (*string, error) {
-	return createHTMLTableWithStyle(startCol, startRow, endCol, endRow, extractor, nil)
+func createHTMLTable(ctx context.Context, startCol int, startRow int, endCol int, endRow int, extractor func(cellRange string) (string, error)) (*string, error) {
+	return createHTMLTableWithStyle(ctx, startCol, startRow, endCol, endRow, extractor, nil)
 }
 
-func CreateHTMLTableOfValuesWithStyle(worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
-	return createHTMLTableWithStyle(startCol, startRow, endCol, endRow,
+func CreateHTMLTableOfValuesWithStyle(ctx context.Context, worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
+	return createHTMLTableWithStyle(ctx, startCol, startRow, endCol, endRow,
 		func(cellRange string) (string, error) {
 			return worksheet.GetValue(cellRange)
 		},
@@ -358,8 +469,8 @@ func CreateHTMLTableOfValuesWithStyle(worksheet excel.Worksheet, startCol int, s
 		})
 }
 
-func CreateHTMLTableOfFormulaWithStyle(worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
-	return createHTMLTableWithStyle(startCol, startRow, endCol, endRow,
+func CreateHTMLTableOfFormulaWithStyle(ctx context.Context, worksheet excel.Worksheet, startCol int, startRow int, endCol int, endRow int) (*string, error) {
+	return createHTMLTableWithStyle(ctx, startCol, startRow, endCol, endRow,
 		func(cellRange string) (string, error) {
 			return worksheet.GetFormula(cellRange)
 		},
@@ -368,65 +479,13 @@ func CreateHTMLTableOfFormulaWithStyle(worksheet excel.Worksheet, startCol int,
 		})
 }
 
-func createHTMLTableWithStyle(startCol int, startRow int, endCol int, endRow int, extractor func(cellRange string) (string, error), styleExtractor func(cellRange string) (*excel.CellStyle, error)) (*string, error) {
-	registry := NewStyleRegistry()
-
-	// データとスタイルを収集
-	var result strings.Builder
-	result.WriteString("<table>\n<tr><th></th>")
-
-	// 列アドレスの出力
-	for col := startCol; col <= endCol; col++ {
-		name, _ := excelize.ColumnNumberToName(col)
-		result.WriteString(fmt.Sprintf("<th>%s</th>", name))
-	}
-	result.WriteString("</tr>\n")
-
-	// データの出力とスタイル登録
-	for row := startRow; row <= endRow; row++ {
-		result.WriteString("<tr>")
-		result.WriteString(fmt.Sprintf("<th>%d</th>", row))
-
-		for col := startCol; col <= endCol; col++ {
-			axis, _ := excelize.CoordinatesToCellName(col, row)
-			value, _ := extractor(axis)
-
-			var tdTag string
-			if styleExtractor != nil {
-				cellStyle, err := styleExtractor(axis)
-				if err == nil && cellStyle != nil {
-					styleIDs := registry.RegisterStyle(cellStyle)
-					if len(styleIDs) > 0 {
-						tdTag = fmt.Sprintf("<td style-ref=\"%s\">", strings.Join(styleIDs, " "))
-					} else {
-						tdTag = "<td>"
-					}
-				} else {
-					tdTag = "<td>"
-				}
-			} else {
-				tdTag = "<td>"
-			}
-
-			result.WriteString(fmt.Sprintf("%s%s</td>", tdTag, strings.ReplaceAll(html.EscapeString(value), "\n", "<br>")))
-		}
-		result.WriteString("</tr>\n")
-	}
-
-	result.WriteString("</table>")
-
-	// スタイル定義とテーブルを結合
-	var finalResult strings.Builder
-	styleDefinitions := registry.GenerateStyleDefinitions()
-	if styleDefinitions != "" {
-		finalResult.WriteString(styleDefinitions)
+func createHTMLTableWithStyle(ctx context.Context, startCol int, startRow int, endCol int, endRow int, extractor func(cellRange string) (string, error), styleExtractor func(cellRange string) (*excel.CellStyle, error)) (*string, error) {
+	table, err := readTableData(ctx, startCol, startRow, endCol, endRow, extractor, styleExtractor)
+	if err != nil {
+		return nil, err
 	}
-
-	finalResult.WriteString("<h2>Sheet Data</h2>\n")
-	finalResult.WriteString(result.String())
-
-	finalResultStr := finalResult.String()
-	return &finalResultStr, nil
+	result := table.renderHTML()
+	return &result, nil
 }
 
 func AbsolutePathTest() z.Test[*string] {
@@ -434,6 +493,15 @@ func AbsolutePathTest() z.Test[*string] {
 		Func: func(path *string, ctx z.Ctx) {
 			if !filepath.IsAbs(*path) {
 				ctx.AddIssue(ctx.Issue().SetMessage(fmt.Sprintf("Path '%s' is not absolute", *path)))
+				return
+			}
+			config, issues := LoadConfig()
+			if issues != nil {
+				ctx.AddIssue(ctx.Issue().SetMessage("Invalid server configuration"))
+				return
+			}
+			if err := validatePathInAllowedRoots(*path, config.AllowedRoots()); err != nil {
+				ctx.AddIssue(ctx.Issue().SetMessage(err.Error()))
 			}
 		},
 	}
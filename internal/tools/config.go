@@ -1,16 +1,61 @@
 package tools
 
 import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
 	z "github.com/Oudwins/zog"
 	"github.com/Oudwins/zog/zenv"
 )
 
 type EnvConfig struct {
-	EXCEL_MCP_PAGING_CELLS_LIMIT int
+	EXCEL_MCP_PAGING_CELLS_LIMIT          int
+	EXCEL_MCP_PAGING_STRATEGY             string
+	EXCEL_MCP_PAGING_TOKENS_LIMIT         int
+	EXCEL_MCP_TRANSPORT                   string
+	EXCEL_MCP_HTTP_ADDR                   string
+	EXCEL_MCP_HTTP_BASE_PATH              string
+	EXCEL_MCP_HTTP_AUTH_TOKEN             string
+	EXCEL_MCP_ALLOWED_ROOTS               string
+	EXCEL_MCP_READ_ONLY                   bool
+	EXCEL_MCP_ENABLED_TOOLS               string
+	EXCEL_MCP_DISABLED_TOOLS              string
+	EXCEL_MCP_WORKBOOK_CACHE              bool
+	EXCEL_MCP_WORKBOOK_CACHE_IDLE_TIMEOUT int
+	EXCEL_MCP_BACKUP_GENERATIONS          int
+	EXCEL_MCP_SNAPSHOT_LIMIT              int
+	EXCEL_MCP_SNAPSHOT_DIR                string
 }
 
+var toolNameListPattern = regexp.MustCompile(`^[a-z0-9_,\s]*$`)
+
 var configSchema = z.Struct(z.Shape{
-	"EXCEL_MCP_PAGING_CELLS_LIMIT": z.Int().GT(0).Default(4000),
+	"EXCEL_MCP_PAGING_CELLS_LIMIT":  z.Int().GT(0).Default(4000),
+	"EXCEL_MCP_PAGING_STRATEGY":     z.String().OneOf(pagingStrategies).Default(PagingStrategyCells),
+	"EXCEL_MCP_PAGING_TOKENS_LIMIT": z.Int().GT(0).Default(10000),
+	"EXCEL_MCP_TRANSPORT":           z.String().OneOf([]string{"stdio", "streamable-http", "sse"}).Default("stdio"),
+	"EXCEL_MCP_HTTP_ADDR":           z.String().Default("127.0.0.1:8000"),
+	"EXCEL_MCP_HTTP_BASE_PATH":      z.String().HasPrefix("/").Default("/mcp"),
+	"EXCEL_MCP_HTTP_AUTH_TOKEN":     z.String(),
+	"EXCEL_MCP_ALLOWED_ROOTS": z.String().TestFunc(func(value *string, ctx z.Ctx) bool {
+		for _, root := range parseAllowedRoots(*value) {
+			if !filepath.IsAbs(root) {
+				return false
+			}
+		}
+		return true
+	}, z.Message("all allowed roots must be absolute paths")),
+	"EXCEL_MCP_READ_ONLY":                   z.Bool().Default(false),
+	"EXCEL_MCP_ENABLED_TOOLS":               z.String().Match(toolNameListPattern, z.Message("must be a comma-separated list of tool names")),
+	"EXCEL_MCP_DISABLED_TOOLS":              z.String().Match(toolNameListPattern, z.Message("must be a comma-separated list of tool names")),
+	"EXCEL_MCP_WORKBOOK_CACHE":              z.Bool().Default(false),
+	"EXCEL_MCP_WORKBOOK_CACHE_IDLE_TIMEOUT": z.Int().GT(0).Default(300),
+	"EXCEL_MCP_BACKUP_GENERATIONS":          z.Int().GTE(0).Default(0),
+	"EXCEL_MCP_SNAPSHOT_LIMIT":              z.Int().GTE(0).Default(10),
+	"EXCEL_MCP_SNAPSHOT_DIR": z.String().TestFunc(func(value *string, ctx z.Ctx) bool {
+		return *value == "" || filepath.IsAbs(*value)
+	}, z.Message("must be an absolute path")),
 })
 
 func LoadConfig() (EnvConfig, z.ZogIssueMap) {
@@ -18,3 +63,31 @@ func LoadConfig() (EnvConfig, z.ZogIssueMap) {
 	issues := configSchema.Parse(zenv.NewDataProvider(), &config)
 	return config, issues
 }
+
+// AllowedRoots returns the root directories which tools are restricted to.
+// An empty list means no restriction.
+func (c EnvConfig) AllowedRoots() []string {
+	return parseAllowedRoots(c.EXCEL_MCP_ALLOWED_ROOTS)
+}
+
+// EnabledTools returns the names of the tools to be served.
+// An empty list means all tools are served.
+func (c EnvConfig) EnabledTools() []string {
+	return parseToolNames(c.EXCEL_MCP_ENABLED_TOOLS)
+}
+
+// DisabledTools returns the names of the tools not to be served.
+func (c EnvConfig) DisabledTools() []string {
+	return parseToolNames(c.EXCEL_MCP_DISABLED_TOOLS)
+}
+
+func parseToolNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
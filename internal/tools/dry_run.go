@@ -0,0 +1,310 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+// maxDryRunChanges is the maximum number of changed cells listed in a dry run result
+const maxDryRunChanges = 500
+
+// CellChange describes how a dry run would change a cell.
+// Formulas are empty for cells without a formula, and styles are rendered in YAML flow format.
+type CellChange struct {
+	Sheet      string `json:"sheet"`
+	Cell       string `json:"cell"`
+	OldValue   string `json:"oldValue"`
+	NewValue   string `json:"newValue"`
+	OldFormula string `json:"oldFormula,omitempty"`
+	NewFormula string `json:"newFormula,omitempty"`
+	OldStyle   string `json:"oldStyle,omitempty"`
+	NewStyle   string `json:"newStyle,omitempty"`
+}
+
+// WorkbookDiff is the set of changes between two versions of a workbook.
+type WorkbookDiff struct {
+	AddedSheets   []string     `json:"addedSheets"`
+	RemovedSheets []string     `json:"removedSheets"`
+	Changes       []CellChange `json:"changes"`
+	// TotalChanges may exceed the length of Changes when the list is truncated
+	TotalChanges int  `json:"totalChanges"`
+	Truncated    bool `json:"truncated"`
+}
+
+// cellState is the content of a cell compared by a dry run.
+type cellState struct {
+	value   string
+	formula string
+	style   string
+}
+
+// runDryRun runs a mutating operation against a temporary copy of the workbook and reports the
+// cell-level differences between the copy and the original. The original file is never written.
+// The operation receives the path of the copy in place of fileAbsolutePath.
+func runDryRun(ctx context.Context, fileAbsolutePath string, operation func(path string) (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+	tempDir, err := os.MkdirTemp("", "excel-mcp-dry-run-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a directory for the dry run: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	copyPath := filepath.Join(tempDir, filepath.Base(fileAbsolutePath))
+	originalPath := fileAbsolutePath
+	if content, ok := excel.UnsavedWorkbookContent(fileAbsolutePath); ok {
+		// Start from the changes which are not written from the workbook cache yet
+		originalPath = filepath.Join(tempDir, "original", filepath.Base(fileAbsolutePath))
+		if err := os.Mkdir(filepath.Dir(originalPath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create a directory for the dry run: %w", err)
+		}
+		if err := os.WriteFile(originalPath, content, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to copy the workbook for the dry run: %w", err)
+		}
+	}
+	if _, err := os.Stat(originalPath); err == nil {
+		if err := excel.CopyFile(originalPath, copyPath); err != nil {
+			return nil, fmt.Errorf("failed to copy the workbook for the dry run: %w", err)
+		}
+	}
+	// The copy must not stay in the workbook cache after the directory is removed
+	defer excel.DiscardCachedWorkbook(copyPath)
+
+	result, err := operation(copyPath)
+	if err != nil {
+		return nil, err
+	}
+	replaceResultText(result, copyPath, fileAbsolutePath)
+	if result.IsError {
+		return result, nil
+	}
+	if _, err := excel.FlushCachedWorkbook(copyPath); err != nil {
+		return nil, err
+	}
+
+	diff, err := diffWorkbookFiles(ctx, originalPath, copyPath)
+	if err != nil {
+		return nil, err
+	}
+	diffJSON, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	text := "# Dry Run\n"
+	text += fmt.Sprintf("Nothing was written to %s. The operation would make the following changes.\n", fileAbsolutePath)
+	text += "## Operation Result\n"
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text += textContent.Text
+		}
+	}
+	text += "\n## Changes\n"
+	text += string(diffJSON)
+	dryRunResult := mcp.NewToolResultText(text)
+	if output := writeOutputOf(result); output != nil {
+		output.DryRun = true
+		output.Changes = diff
+		dryRunResult.StructuredContent = result.StructuredContent
+	}
+	return dryRunResult, nil
+}
+
+// replaceResultText rewrites the path of the temporary copy in the text contents of the result.
+func replaceResultText(result *mcp.CallToolResult, oldPath string, newPath string) {
+	for i, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			textContent.Text = strings.ReplaceAll(textContent.Text, oldPath, newPath)
+			result.Content[i] = textContent
+		}
+	}
+}
+
+// diffWorkbookFiles compares two workbook files. A missing before file is treated as an empty workbook.
+func diffWorkbookFiles(ctx context.Context, beforePath string, afterPath string) (*WorkbookDiff, error) {
+	var before *excelize.File
+	if _, err := os.Stat(beforePath); err == nil {
+		before, err = excelize.OpenFile(beforePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", beforePath, err)
+		}
+		defer before.Close()
+	}
+	after, err := excelize.OpenFile(afterPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the dry run result: %w", err)
+	}
+	defer after.Close()
+	return diffWorkbooks(ctx, before, after)
+}
+
+// diffWorkbooks compares the cells of the sheets in both workbooks.
+// Sheets only in after are compared against empty sheets, and sheets only in before are reported by name.
+func diffWorkbooks(ctx context.Context, before *excelize.File, after *excelize.File) (*WorkbookDiff, error) {
+	diff := &WorkbookDiff{
+		AddedSheets:   []string{},
+		RemovedSheets: []string{},
+		Changes:       []CellChange{},
+	}
+	var beforeSheets []string
+	if before != nil {
+		beforeSheets = before.GetSheetList()
+	}
+	afterSheets := after.GetSheetList()
+	for _, sheetName := range beforeSheets {
+		if !slices.Contains(afterSheets, sheetName) {
+			diff.RemovedSheets = append(diff.RemovedSheets, sheetName)
+		}
+	}
+
+	for _, sheetName := range afterSheets {
+		beforeSheet := before
+		if !slices.Contains(beforeSheets, sheetName) {
+			diff.AddedSheets = append(diff.AddedSheets, sheetName)
+			beforeSheet = nil
+		}
+		startCol, startRow, endCol, endRow, ok := unionUsedRange(sheetName, beforeSheet, after)
+		if !ok {
+			continue
+		}
+		for row := startRow; row <= endRow; row++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for col := startCol; col <= endCol; col++ {
+				cell, err := excelize.CoordinatesToCellName(col, row)
+				if err != nil {
+					return nil, err
+				}
+				oldState, err := readCellState(beforeSheet, sheetName, cell)
+				if err != nil {
+					return nil, err
+				}
+				newState, err := readCellState(after, sheetName, cell)
+				if err != nil {
+					return nil, err
+				}
+				if oldState == newState {
+					continue
+				}
+				diff.TotalChanges++
+				if len(diff.Changes) >= maxDryRunChanges {
+					diff.Truncated = true
+					continue
+				}
+				diff.Changes = append(diff.Changes, CellChange{
+					Sheet:      sheetName,
+					Cell:       cell,
+					OldValue:   oldState.value,
+					NewValue:   newState.value,
+					OldFormula: oldState.formula,
+					NewFormula: newState.formula,
+					OldStyle:   oldState.style,
+					NewStyle:   newState.style,
+				})
+			}
+		}
+	}
+	return diff, nil
+}
+
+// unionUsedRange returns the range covering the cells used by the sheet in either workbook.
+// The recorded dimension can be stale, so it is widened by the rows and columns which actually have values
+// and by the cells which only have a style.
+func unionUsedRange(sheetName string, before *excelize.File, after *excelize.File) (int, int, int, int, bool) {
+	startCol, startRow, endCol, endRow := 0, 0, 0, 0
+	found := false
+	extend := func(sc, sr, ec, er int) {
+		if !found {
+			startCol, startRow, endCol, endRow = sc, sr, ec, er
+			found = true
+			return
+		}
+		startCol, startRow = min(startCol, sc), min(startRow, sr)
+		endCol, endRow = max(endCol, ec), max(endRow, er)
+	}
+	for _, file := range []*excelize.File{before, after} {
+		if file == nil {
+			continue
+		}
+		if dimension, err := file.GetSheetDimension(sheetName); err == nil && dimension != "" {
+			if sc, sr, ec, er, err := excel.ParseRange(dimension); err == nil {
+				extend(sc, sr, ec, er)
+			}
+		}
+		if rows, err := file.GetRows(sheetName, excelize.Options{RawCellValue: true}); err == nil {
+			for i, row := range rows {
+				if len(row) > 0 {
+					extend(1, i+1, len(row), i+1)
+				}
+			}
+		}
+		if cols, rows := cellsExtent(file, sheetName); cols > 0 && rows > 0 {
+			extend(1, 1, cols, rows)
+		}
+	}
+	return startCol, startRow, endCol, endRow, found
+}
+
+// cellsExtent returns the number of columns and rows of the sheet including the cells without a value,
+// e.g. the cells which only have a style.
+func cellsExtent(file *excelize.File, sheetName string) (int, int) {
+	cols, rows := 0, 0
+	if iterator, err := file.Cols(sheetName); err == nil {
+		for iterator.Next() {
+			cols++
+		}
+	}
+	if iterator, err := file.Rows(sheetName); err == nil {
+		for iterator.Next() {
+			rows++
+		}
+		iterator.Close()
+	}
+	return cols, rows
+}
+
+func readCellState(file *excelize.File, sheetName string, cell string) (cellState, error) {
+	if file == nil {
+		return cellState{}, nil
+	}
+	value, err := file.GetCellValue(sheetName, cell)
+	if err != nil {
+		return cellState{}, fmt.Errorf("failed to get value of %s!%s: %w", sheetName, cell, err)
+	}
+	formula, err := file.GetCellFormula(sheetName, cell)
+	if err != nil {
+		return cellState{}, fmt.Errorf("failed to get formula of %s!%s: %w", sheetName, cell, err)
+	}
+	if formula != "" && !strings.HasPrefix(formula, "=") {
+		formula = "=" + formula
+	}
+	styleID, err := file.GetCellStyle(sheetName, cell)
+	if err != nil {
+		return cellState{}, fmt.Errorf("failed to get style of %s!%s: %w", sheetName, cell, err)
+	}
+	style := ""
+	if styleID != 0 {
+		// Style 0 is the default style of the workbook
+		worksheet, err := excel.NewExcelizeExcel(file).FindSheet(sheetName)
+		if err != nil {
+			return cellState{}, err
+		}
+		defer worksheet.Release()
+		cellStyle, err := worksheet.GetCellStyle(cell)
+		if err != nil {
+			return cellState{}, err
+		}
+		if cellStyle != nil && !NewStyleRegistry().isEmptyStyle(cellStyle) {
+			style = convertToYAMLFlow(cellStyle)
+		}
+	}
+	return cellState{value: value, formula: formula, style: style}, nil
+}
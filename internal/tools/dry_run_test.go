@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"crypto/md5"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDiffWorkbooks(t *testing.T) {
+	before := excelize.NewFile()
+	defer before.Close()
+	before.SetCellValue("Sheet1", "A1", "name")
+	before.SetCellValue("Sheet1", "B1", "total")
+	before.NewSheet("Old")
+
+	after := excelize.NewFile()
+	defer after.Close()
+	after.SetCellValue("Sheet1", "A1", "name")
+	after.SetCellFormula("Sheet1", "B1", "SUM(C1:C2)")
+	after.SetCellValue("Sheet1", "C3", "added")
+	styleID, err := after.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after.SetCellStyle("Sheet1", "A1", "A1", styleID)
+	// A cell with only a style is out of the dimension
+	after.SetCellStyle("Sheet1", "D5", "D5", styleID)
+	after.NewSheet("New")
+
+	diff, err := diffWorkbooks(context.Background(), before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.AddedSheets) != 1 || diff.AddedSheets[0] != "New" {
+		t.Errorf("AddedSheets = %v", diff.AddedSheets)
+	}
+	if len(diff.RemovedSheets) != 1 || diff.RemovedSheets[0] != "Old" {
+		t.Errorf("RemovedSheets = %v", diff.RemovedSheets)
+	}
+
+	changes := map[string]CellChange{}
+	for _, change := range diff.Changes {
+		changes[change.Sheet+"!"+change.Cell] = change
+	}
+	if len(changes) != 4 || diff.TotalChanges != 4 || diff.Truncated {
+		t.Fatalf("unexpected changes: %+v", diff)
+	}
+	if change := changes["Sheet1!A1"]; change.OldStyle != "" || !strings.Contains(change.NewStyle, "bold: true") {
+		t.Errorf("unexpected style change: %+v", change)
+	}
+	if change := changes["Sheet1!D5"]; change.NewValue != "" || !strings.Contains(change.NewStyle, "bold: true") {
+		t.Errorf("unexpected style change of an empty cell: %+v", change)
+	}
+	if change := changes["Sheet1!B1"]; change.OldValue != "total" || change.NewFormula != "=SUM(C1:C2)" {
+		t.Errorf("unexpected formula change: %+v", change)
+	}
+	if change := changes["Sheet1!C3"]; change.OldValue != "" || change.NewValue != "added" {
+		t.Errorf("unexpected value change: %+v", change)
+	}
+}
+
+func TestRunDryRunDoesNotWriteWorkbook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	file := excelize.NewFile()
+	for row := 1; row <= 3; row++ {
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		file.SetCellValue("Sheet1", cell, row)
+	}
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runDryRun(context.Background(), path, func(path string) (*mcp.CallToolResult, error) {
+		return deleteRows(path, "Sheet1", 1, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "# Dry Run\n") || !strings.Contains(text, `"totalChanges": 3`) {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md5.Sum(current) != md5.Sum(original) {
+		t.Error("the workbook was modified by the dry run")
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -30,9 +29,20 @@ var excelAddChartArgumentsSchema = z.Struct(z.Shape{
 	"position":         z.String().Required(),
 })
 
-func AddExcelAddChartTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_add_chart",
+type ExcelAddChartOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	ChartType string `json:"chartType"`
+	Position  string `json:"position"`
+	WriteOutput
+}
+
+func AddExcelAddChartTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_add_chart",
 		mcp.WithDescription("Create a chart in the Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelAddChartOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -90,5 +100,10 @@ func addChart(fileAbsolutePath string, sheetName string, chartType string, dataR
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Chart (%s) created at %s in sheet [%s].\n", chartType, position, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelAddChartOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		ChartType: chartType,
+		Position:  position,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -28,9 +27,19 @@ var excelAddCommentArgumentsSchema = z.Struct(z.Shape{
 	"text":             z.String().Required(),
 })
 
-func AddExcelAddCommentTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_add_comment",
+type ExcelAddCommentOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Cell      string `json:"cell"`
+	WriteOutput
+}
+
+func AddExcelAddCommentTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_add_comment",
 		mcp.WithDescription("Add a comment to a cell in the Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelAddCommentOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -84,5 +93,9 @@ func addComment(fileAbsolutePath string, sheetName string, cell string, author s
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Comment added to cell %s in sheet [%s].\n", cell, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelAddCommentOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Cell:      cell,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -32,9 +31,20 @@ var excelAddDataValidationArgumentsSchema = z.Struct(z.Shape{
 	"allowBlank":       z.Bool().Default(true),
 })
 
-func AddExcelAddDataValidationTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_add_data_validation",
+type ExcelAddDataValidationOutput struct {
+	Backend        string `json:"backend"`
+	SheetName      string `json:"sheetName"`
+	Range          string `json:"range"`
+	ValidationType string `json:"validationType"`
+	WriteOutput
+}
+
+func AddExcelAddDataValidationTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_add_data_validation",
 		mcp.WithDescription("Add data validation rules to a range of cells"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelAddDataValidationOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -95,5 +105,10 @@ func addDataValidation(fileAbsolutePath string, sheetName string, validationRang
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Data validation (%s) added to range [%s] in sheet [%s].\n", validationType, validationRange, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelAddDataValidationOutput{
+		Backend:        workbook.GetBackendName(),
+		SheetName:      sheetName,
+		Range:          validationRange,
+		ValidationType: validationType,
+	}, result), nil
 }
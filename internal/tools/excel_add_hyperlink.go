@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -28,9 +27,20 @@ var excelAddHyperlinkArgumentsSchema = z.Struct(z.Shape{
 	"display":          z.String(),
 })
 
-func AddExcelAddHyperlinkTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_add_hyperlink",
+type ExcelAddHyperlinkOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Cell      string `json:"cell"`
+	URL       string `json:"url"`
+	WriteOutput
+}
+
+func AddExcelAddHyperlinkTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_add_hyperlink",
 		mcp.WithDescription("Add a hyperlink to a cell in the Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelAddHyperlinkOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -84,5 +94,10 @@ func addHyperlink(fileAbsolutePath string, sheetName string, cell string, url st
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Hyperlink added to cell %s in sheet [%s] -> %s\n", cell, html.EscapeString(sheetName), html.EscapeString(url))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelAddHyperlinkOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Cell:      cell,
+		URL:       url,
+	}, result), nil
 }
@@ -0,0 +1,547 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelBatchArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+	DryRun           bool   `zog:"dryRun"`
+}
+
+var excelBatchArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+	"dryRun":           z.Bool().Default(false),
+})
+
+type ExcelBatchOutput struct {
+	Backend string                 `json:"backend"`
+	Steps   []ExcelBatchStepOutput `json:"steps"`
+	WriteOutput
+}
+
+type ExcelBatchStepOutput struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// batchStep applies an operation to the workbook and returns the message of the step.
+type batchStep func(ctx context.Context, workbook excel.Excel) (string, error)
+
+// batchOperation is an operation of excel_batch.
+// Its arguments are the same as the tool except fileAbsolutePath, so they are validated by the schema of the tool.
+type batchOperation struct {
+	// tool provides the same operation. The operation is unavailable while the tool is disabled.
+	tool    string
+	prepare func(args map[string]any) (batchStep, error)
+}
+
+var batchOperations = map[string]batchOperation{
+	"write": {tool: "excel_write_to_sheet", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelWriteToSheetArguments{}
+		if err := parseBatchArguments(excelWriteToSheetArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		values, err := parseValuesArgument(args["values"])
+		if err != nil {
+			return nil, err
+		}
+		startCol, startRow, endCol, endRow, err := excel.ParseRange(a.Range)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateValuesSize(values, startCol, startRow, endCol, endRow); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			if a.NewSheet {
+				if err := workbook.CreateNewSheet(a.SheetName); err != nil {
+					return "", err
+				}
+			}
+			var hiddenCells []string
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				var err error
+				_, hiddenCells, err = setValues(worksheet, startCol, startRow, values)
+				return err
+			})
+			message := fmt.Sprintf("Values written to [%s] in sheet [%s].", a.Range, html.EscapeString(a.SheetName))
+			if len(hiddenCells) > 0 {
+				message += " " + hiddenCellsNotice(hiddenCells)
+			}
+			return message, err
+		}, nil
+	}},
+	"format": {tool: "excel_format_range", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelFormatRangeArguments{}
+		if err := parseBatchArguments(excelFormatRangeArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		startCol, startRow, endCol, endRow, err := excel.ParseRange(a.Range)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateStylesSize(a.Styles, startCol, startRow, endCol, endRow); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return setStyles(worksheet, startCol, startRow, a.Styles)
+			})
+			return fmt.Sprintf("Styles applied to [%s] in sheet [%s].", a.Range, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"merge": {tool: "excel_merge_cells", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelMergeCellsArguments{}
+		if err := parseBatchArguments(excelMergeCellsArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.MergeCells(a.Range)
+			})
+			return fmt.Sprintf("Cells [%s] merged in sheet [%s].", a.Range, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"unmerge": {tool: "excel_unmerge_cells", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelUnmergeCellsArguments{}
+		if err := parseBatchArguments(excelUnmergeCellsArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.UnmergeCells(a.Range)
+			})
+			return fmt.Sprintf("Cells [%s] unmerged in sheet [%s].", a.Range, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"add_chart": {tool: "excel_add_chart", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelAddChartArguments{}
+		if err := parseBatchArguments(excelAddChartArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.AddChart(a.Position, a.ChartType, a.DataRange, a.Title)
+			})
+			return fmt.Sprintf("Chart (%s) created at %s in sheet [%s].", a.ChartType, a.Position, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"set_named_range": {tool: "excel_set_named_range", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelSetNamedRangeArguments{}
+		if err := parseBatchArguments(excelSetNamedRangeArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := workbook.SetDefinedName(a.Name, a.RefersTo, a.Scope)
+			return fmt.Sprintf("Named range \"%s\" set to %s.", html.EscapeString(a.Name), html.EscapeString(a.RefersTo)), err
+		}, nil
+	}},
+	"create_table": {tool: "excel_create_table", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelCreateTableArguments{}
+		if err := parseBatchArguments(excelCreateTableArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.AddTable(a.Range, a.TableName)
+			})
+			return fmt.Sprintf("Table [%s] created.", html.EscapeString(a.TableName)), err
+		}, nil
+	}},
+	"insert_rows": {tool: "excel_insert_rows", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelInsertRowsArguments{}
+		if err := parseBatchArguments(excelInsertRowsArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.InsertRows(a.Row, a.Count)
+			})
+			return fmt.Sprintf("Inserted %d row(s) at row %d in sheet [%s].", a.Count, a.Row, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"delete_rows": {tool: "excel_delete_rows", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelDeleteRowsArguments{}
+		if err := parseBatchArguments(excelDeleteRowsArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.DeleteRows(a.Row, a.Count)
+			})
+			return fmt.Sprintf("Deleted %d row(s) starting at row %d in sheet [%s].", a.Count, a.Row, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"insert_columns": {tool: "excel_insert_columns", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelInsertColumnsArguments{}
+		if err := parseBatchArguments(excelInsertColumnsArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.InsertColumns(a.Column, a.Count)
+			})
+			return fmt.Sprintf("Inserted %d column(s) at column %s in sheet [%s].", a.Count, a.Column, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"delete_columns": {tool: "excel_delete_columns", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelDeleteColumnsArguments{}
+		if err := parseBatchArguments(excelDeleteColumnsArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.DeleteColumns(a.Column, a.Count)
+			})
+			return fmt.Sprintf("Deleted %d column(s) starting at column %s in sheet [%s].", a.Count, a.Column, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"set_column_width": {tool: "excel_set_column_width", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelSetColumnWidthArguments{}
+		if err := parseBatchArguments(excelSetColumnWidthArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			startCol, endCol := a.Column, a.Column
+			if parts := strings.SplitN(a.Column, ":", 2); len(parts) == 2 {
+				startCol, endCol = parts[0], parts[1]
+			}
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.SetColumnWidth(startCol, endCol, a.Width)
+			})
+			return fmt.Sprintf("Column width set to %.1f for [%s] in sheet [%s].", a.Width, a.Column, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"set_row_height": {tool: "excel_set_row_height", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelSetRowHeightArguments{}
+		if err := parseBatchArguments(excelSetRowHeightArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		if a.EndRow == 0 || a.EndRow < a.Row {
+			a.EndRow = a.Row
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				for r := a.Row; r <= a.EndRow; r++ {
+					if err := worksheet.SetRowHeight(r, a.Height); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			return fmt.Sprintf("Row height set to %.1f for rows %d-%d in sheet [%s].", a.Height, a.Row, a.EndRow, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"freeze_panes": {tool: "excel_freeze_panes", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelFreezePanesArguments{}
+		if err := parseBatchArguments(excelFreezePanesArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.FreezePanes(a.Cell)
+			})
+			return fmt.Sprintf("Panes frozen at %s in sheet [%s].", a.Cell, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"add_comment": {tool: "excel_add_comment", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelAddCommentArguments{}
+		if err := parseBatchArguments(excelAddCommentArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.AddComment(a.Cell, a.Author, a.Text)
+			})
+			return fmt.Sprintf("Comment added to cell %s in sheet [%s].", a.Cell, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"add_hyperlink": {tool: "excel_add_hyperlink", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelAddHyperlinkArguments{}
+		if err := parseBatchArguments(excelAddHyperlinkArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.AddHyperlink(a.Cell, a.Url, a.Display)
+			})
+			return fmt.Sprintf("Hyperlink added to cell %s in sheet [%s] -> %s", a.Cell, html.EscapeString(a.SheetName), html.EscapeString(a.Url)), err
+		}, nil
+	}},
+	"add_data_validation": {tool: "excel_add_data_validation", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelAddDataValidationArguments{}
+		if err := parseBatchArguments(excelAddDataValidationArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.AddDataValidation(a.Range, a.Type, a.Formula1, a.Formula2, a.AllowBlank)
+			})
+			return fmt.Sprintf("Data validation (%s) added to range [%s] in sheet [%s].", a.Type, a.Range, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"set_conditional_format": {tool: "excel_set_conditional_format", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelSetConditionalFormatArguments{}
+		if err := parseBatchArguments(excelSetConditionalFormatArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				return worksheet.SetConditionalFormat(a.Range, a.Type, a.Criteria, a.Value, a.Value2, a.FontColor, a.BgColor)
+			})
+			return fmt.Sprintf("Conditional formatting (%s) applied to %s in sheet [%s].", a.Type, a.Range, html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"find_replace": {tool: "excel_find_replace", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelFindReplaceArguments{}
+		if err := parseBatchArguments(excelFindReplaceArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			count := 0
+			err := applyToSheet(workbook, a.SheetName, func(worksheet excel.Worksheet) error {
+				var err error
+				count, err = worksheet.FindReplace(ctx, a.Range, a.Find, a.Replace, a.MatchCase, a.MatchEntireCell, func(int, int) {})
+				return err
+			})
+			return fmt.Sprintf("Replaced %d occurrence(s) of \"%s\" with \"%s\" in sheet [%s].", count, html.EscapeString(a.Find), html.EscapeString(a.Replace), html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+	"copy_sheet": {tool: "excel_copy_sheet", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelCopySheetArguments{}
+		if err := parseBatchArguments(excelCopySheetArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := workbook.CopySheet(a.SrcSheetName, a.DstSheetName)
+			return fmt.Sprintf("Sheet [%s] copied to [%s].", html.EscapeString(a.SrcSheetName), html.EscapeString(a.DstSheetName)), err
+		}, nil
+	}},
+	"rename_sheet": {tool: "excel_rename_sheet", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelRenameSheetArguments{}
+		if err := parseBatchArguments(excelRenameSheetArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := workbook.RenameSheet(a.OldSheetName, a.NewSheetName)
+			return fmt.Sprintf("Sheet [%s] renamed to [%s].", html.EscapeString(a.OldSheetName), html.EscapeString(a.NewSheetName)), err
+		}, nil
+	}},
+	"delete_sheet": {tool: "excel_delete_sheet", prepare: func(args map[string]any) (batchStep, error) {
+		a := ExcelDeleteSheetArguments{}
+		if err := parseBatchArguments(excelDeleteSheetArgumentsSchema, args, &a); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, workbook excel.Excel) (string, error) {
+			err := workbook.DeleteSheet(a.SheetName)
+			return fmt.Sprintf("Sheet [%s] deleted.", html.EscapeString(a.SheetName)), err
+		}, nil
+	}},
+}
+
+func AddExcelBatchTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_batch",
+		mcp.WithDescription("Run multiple operations on a workbook in order and save it once. If any operation fails, none of the operations are saved. Not available while the workbook is open in Excel, since operations through Excel cannot be rolled back"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelBatchOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+		mcp.WithArray("operations",
+			mcp.Required(),
+			mcp.Description("Operations to run in order. Each operation has a \"type\" and the same arguments as the corresponding tool except fileAbsolutePath (e.g., {\"type\": \"merge\", \"sheetName\": \"Sheet1\", \"range\": \"A1:C1\"})"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"type": map[string]any{
+						"type": "string",
+						"enum": batchOperationTypes(),
+					},
+				},
+				"required": []string{"type"},
+			}),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
+	), WithRecovery(handleBatch))
+}
+
+func handleBatch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelBatchArguments{}
+	if issues := excelBatchArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	operationsArg, ok := request.GetArguments()["operations"].([]any)
+	if !ok || len(operationsArg) == 0 {
+		return imcp.NewToolResultInvalidArgumentError("operations must be a non-empty array of objects"), nil
+	}
+	operations := make([]map[string]any, len(operationsArg))
+	for i, operation := range operationsArg {
+		if operations[i], ok = operation.(map[string]any); !ok {
+			return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("operations[%d] must be an object", i)), nil
+		}
+	}
+	// Operations of disabled tools must not be reachable through the batch
+	isAvailable := func(tool string) bool { return true }
+	if mcpServer := server.ServerFromContext(ctx); mcpServer != nil {
+		isAvailable = func(tool string) bool { return mcpServer.GetTool(tool) != nil }
+	}
+	if args.DryRun {
+		// Operations are validated against the original path since the copy for the dry run is outside of the allowed roots
+		types, steps, invalid := prepareBatch(args.FileAbsolutePath, operations, isAvailable)
+		if invalid != nil {
+			return invalid, nil
+		}
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return applyBatch(ctx, path, types, steps)
+		})
+	}
+	return runBatch(ctx, args.FileAbsolutePath, operations, isAvailable)
+}
+
+func runBatch(ctx context.Context, fileAbsolutePath string, operations []map[string]any, isAvailable func(tool string) bool) (*mcp.CallToolResult, error) {
+	types, steps, invalid := prepareBatch(fileAbsolutePath, operations, isAvailable)
+	if invalid != nil {
+		return invalid, nil
+	}
+	return applyBatch(ctx, fileAbsolutePath, types, steps)
+}
+
+// prepareBatch validates all operations before touching the workbook.
+// It returns the error result if any operation is invalid.
+func prepareBatch(fileAbsolutePath string, operations []map[string]any, isAvailable func(tool string) bool) ([]string, []batchStep, *mcp.CallToolResult) {
+	types := make([]string, len(operations))
+	steps := make([]batchStep, len(operations))
+	for i, args := range operations {
+		types[i], _ = args["type"].(string)
+		operation, ok := batchOperations[types[i]]
+		if !ok {
+			return nil, nil, imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("operations[%d]: unknown type \"%s\" (available: %s)", i, types[i], strings.Join(batchOperationTypes(), ", ")))
+		}
+		if !isAvailable(operation.tool) {
+			return nil, nil, imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("operations[%d]: type \"%s\" is not available because %s is disabled", i, types[i], operation.tool))
+		}
+		step, err := operation.prepare(withFileAbsolutePath(args, fileAbsolutePath))
+		if err != nil {
+			return nil, nil, imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("operations[%d] (%s): %s", i, types[i], err.Error()))
+		}
+		steps[i] = step
+	}
+	return types, steps, nil
+}
+
+// applyBatch runs the prepared steps on the workbook and saves it only if all of them succeed.
+func applyBatch(ctx context.Context, fileAbsolutePath string, types []string, steps []batchStep) (*mcp.CallToolResult, error) {
+	workbook, release, err := excel.OpenFile(fileAbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+	// Releasing the workbook without saving discards all changes
+	defer release()
+	// Live edits through OLE cannot be reverted, so a failed operation would leave the preceding ones in the workbook
+	if workbook.GetBackendName() != "excelize" {
+		return mcp.NewToolResultError(fmt.Sprintf("excel_batch is not supported by the %s backend because the operations cannot be rolled back. Close the workbook in Excel, or run the operations with the corresponding tools one by one", workbook.GetBackendName())), nil
+	}
+
+	messages := make([]string, len(steps))
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reportProgress(ctx, i, len(steps), "running operations")
+		message, err := step(ctx, workbook)
+		if err != nil {
+			result := fmt.Sprintf("Operation %d (%s) failed: %s\n", i+1, types[i], err.Error())
+			result += "The workbook was not saved, so none of the operations were applied.\n"
+			result += formatBatchSteps(types, messages, i, err, "rolled back")
+			return mcp.NewToolResultError(result), nil
+		}
+		messages[i] = message
+	}
+	reportProgress(ctx, len(steps), len(steps), "running operations")
+
+	if err := workbook.Save(); err != nil {
+		return nil, err
+	}
+
+	output := &ExcelBatchOutput{
+		Backend: workbook.GetBackendName(),
+		Steps:   make([]ExcelBatchStepOutput, len(steps)),
+	}
+	for i := range steps {
+		output.Steps[i] = ExcelBatchStepOutput{Type: types[i], Message: messages[i]}
+	}
+	result := "# Notice\n"
+	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
+	result += fmt.Sprintf("%d operation(s) applied and the workbook saved.\n", len(steps))
+	result += formatBatchSteps(types, messages, -1, nil, "")
+	return mcp.NewToolResultStructured(output, result), nil
+}
+
+// formatBatchSteps lists the result of each step. Steps before the failed one are reported with the undone status
+// and steps after it are reported as skipped.
+func formatBatchSteps(types []string, messages []string, failed int, failure error, undone string) string {
+	result := "## Steps\n"
+	for i, operationType := range types {
+		switch {
+		case failed < 0:
+			result += fmt.Sprintf("%d. %s: ok: %s\n", i+1, operationType, messages[i])
+		case i < failed:
+			result += fmt.Sprintf("%d. %s: %s: %s\n", i+1, operationType, undone, messages[i])
+		case i == failed:
+			result += fmt.Sprintf("%d. %s: failed: %s\n", i+1, operationType, failure.Error())
+		default:
+			result += fmt.Sprintf("%d. %s: skipped\n", i+1, operationType)
+		}
+	}
+	return result
+}
+
+// parseBatchArguments parses the arguments of an operation with the schema of the corresponding tool.
+func parseBatchArguments(schema *z.StructSchema, args map[string]any, dest any) error {
+	if issues := schema.Parse(args, dest); len(issues) != 0 {
+		return imcp.NewZogIssueMapError(issues)
+	}
+	return nil
+}
+
+func withFileAbsolutePath(args map[string]any, fileAbsolutePath string) map[string]any {
+	result := make(map[string]any, len(args)+1)
+	for k, v := range args {
+		result[k] = v
+	}
+	result["fileAbsolutePath"] = fileAbsolutePath
+	return result
+}
+
+func applyToSheet(workbook excel.Excel, sheetName string, apply func(worksheet excel.Worksheet) error) error {
+	worksheet, err := workbook.FindSheet(sheetName)
+	if err != nil {
+		return err
+	}
+	defer worksheet.Release()
+	return apply(worksheet)
+}
+
+func batchOperationTypes() []string {
+	types := make([]string, 0, len(batchOperations))
+	for operationType := range batchOperations {
+		types = append(types, operationType)
+	}
+	slices.Sort(types)
+	return types
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"crypto/md5"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xuri/excelize/v2"
+)
+
+func newTestBatchWorkbook(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	file := excelize.NewFile()
+	defer file.Close()
+	file.SetCellValue("Sheet1", "A1", "before")
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func allBatchOperationsAvailable(string) bool { return true }
+
+func TestRunBatch(t *testing.T) {
+	path := newTestBatchWorkbook(t)
+
+	result, err := runBatch(context.Background(), path, []map[string]any{
+		{"type": "write", "sheetName": "Sheet1", "range": "A1:B1", "values": []any{[]any{"name", 10.0}}},
+		{"type": "merge", "sheetName": "Sheet1", "range": "A2:B2"},
+		{"type": "set_named_range", "name": "Header", "refersTo": "Sheet1!$A$1:$B$1"},
+	}, allBatchOperationsAvailable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "3 operation(s) applied") || !strings.Contains(text, "3. set_named_range: ok") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if value, _ := file.GetCellValue("Sheet1", "A1"); value != "name" {
+		t.Errorf("A1 = %q, want %q", value, "name")
+	}
+	if mergeCells, _ := file.GetMergeCells("Sheet1"); len(mergeCells) != 1 {
+		t.Errorf("expected 1 merged cell, got %d", len(mergeCells))
+	}
+}
+
+func TestRunBatchRollsBackOnFailure(t *testing.T) {
+	path := newTestBatchWorkbook(t)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runBatch(context.Background(), path, []map[string]any{
+		{"type": "write", "sheetName": "Sheet1", "range": "A1", "values": []any{[]any{"after"}}},
+		{"type": "unmerge", "sheetName": "Missing", "range": "A2:B2"},
+		{"type": "merge", "sheetName": "Sheet1", "range": "A2:B2"},
+	}, allBatchOperationsAvailable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Fatal("expected an error result")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"1. write: rolled back", "2. unmerge: failed", "3. merge: skipped"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result does not contain %q: %s", want, text)
+		}
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md5.Sum(current) != md5.Sum(original) {
+		t.Error("the workbook was saved although an operation failed")
+	}
+}
+
+func TestRunBatchValidatesOperations(t *testing.T) {
+	path := newTestBatchWorkbook(t)
+
+	tests := []struct {
+		name        string
+		operation   map[string]any
+		isAvailable func(string) bool
+		want        string
+	}{
+		{"unknown type", map[string]any{"type": "explode"}, allBatchOperationsAvailable, `unknown type "explode"`},
+		{"invalid arguments", map[string]any{"type": "merge", "sheetName": "Sheet1"}, allBatchOperationsAvailable, "range"},
+		{"disabled tool", map[string]any{"type": "delete_sheet", "sheetName": "Sheet1"}, func(tool string) bool { return tool != "excel_delete_sheet" }, "excel_delete_sheet is disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := runBatch(context.Background(), path, []map[string]any{tt.operation}, tt.isAvailable)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !result.IsError || !strings.Contains(text, tt.want) {
+				t.Errorf("unexpected result: %s", text)
+			}
+		})
+	}
+}
+
+func TestHandleBatchDryRunInAllowedRoots(t *testing.T) {
+	path := newTestBatchWorkbook(t)
+	t.Setenv("EXCEL_MCP_ALLOWED_ROOTS", filepath.Dir(path))
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"fileAbsolutePath": path,
+		"dryRun":           true,
+		"operations": []any{
+			map[string]any{"type": "write", "sheetName": "Sheet1", "range": "A1", "values": []any{[]any{"after"}}},
+		},
+	}
+	result, err := handleBatch(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", text)
+	}
+	if !strings.Contains(text, "# Dry Run") || !strings.Contains(text, "after") {
+		t.Errorf("expected the dry run to report the change, got %s", text)
+	}
+	if after, _ := os.ReadFile(path); md5.Sum(after) != md5.Sum(original) {
+		t.Error("expected the dry run to leave the workbook unchanged")
+	}
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelCloseCachedWorkbookArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+	DiscardChanges   bool   `zog:"discardChanges"`
+}
+
+var excelCloseCachedWorkbookArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+	"discardChanges":   z.Bool().Default(false),
+})
+
+type ExcelCloseCachedWorkbookOutput struct {
+	CacheEnabled bool `json:"cacheEnabled"`
+	Closed       bool `json:"closed" jsonschema:"true if the workbook was in the cache and has been closed"`
+}
+
+func AddExcelCloseCachedWorkbookTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_close_cached_workbook",
+		mcp.WithDescription("Close the Excel file held in the workbook cache of the server. Unsaved changes in the cache are written to the file first, unless discardChanges is set. The next call reads the file from disk again"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelCloseCachedWorkbookOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+		mcp.WithBoolean("discardChanges",
+			mcp.Description("Drop the unsaved changes in the cache instead of writing them, e.g. when the file was changed by another program (default: false)"),
+		),
+	), WithRecovery(handleCloseCachedWorkbook))
+}
+
+func handleCloseCachedWorkbook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelCloseCachedWorkbookArguments{}
+	if issues := excelCloseCachedWorkbookArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	return closeCachedWorkbook(args.FileAbsolutePath, args.DiscardChanges)
+}
+
+func closeCachedWorkbook(fileAbsolutePath string, discardChanges bool) (*mcp.CallToolResult, error) {
+	output := &ExcelCloseCachedWorkbookOutput{CacheEnabled: excel.IsWorkbookCacheEnabled()}
+	result := "# Notice\n"
+	if !output.CacheEnabled {
+		result += "Workbook cache is disabled. Nothing to close.\n"
+	} else if discardChanges {
+		if output.Closed = excel.DiscardCachedWorkbook(fileAbsolutePath); output.Closed {
+			result += fmt.Sprintf("Closed cached workbook %s without writing its unsaved changes.\n", fileAbsolutePath)
+		} else {
+			result += fmt.Sprintf("Workbook %s is not cached.\n", fileAbsolutePath)
+		}
+	} else if closed, err := excel.CloseCachedWorkbook(fileAbsolutePath); errors.Is(err, excel.ErrWorkbookChangedOnDisk) {
+		return newWorkbookChangedOnDiskResult(err), nil
+	} else if err != nil {
+		return nil, err
+	} else if closed {
+		output.Closed = true
+		result += fmt.Sprintf("Closed cached workbook %s.\n", fileAbsolutePath)
+	} else {
+		result += fmt.Sprintf("Workbook %s is not cached.\n", fileAbsolutePath)
+	}
+	return mcp.NewToolResultStructured(output, result), nil
+}
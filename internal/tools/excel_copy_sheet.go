@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -19,14 +18,24 @@ type ExcelCopySheetArguments struct {
 }
 
 var excelCopySheetArgumentsSchema = z.Struct(z.Shape{
-	"fileAbsolutePath": z.String().Required(),
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
 	"srcSheetName":     z.String().Required(),
 	"dstSheetName":     z.String().Required(),
 })
 
-func AddExcelCopySheetTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_copy_sheet",
+type ExcelCopySheetOutput struct {
+	Backend      string `json:"backend"`
+	SrcSheetName string `json:"srcSheetName"`
+	DstSheetName string `json:"dstSheetName"`
+	WriteOutput
+}
+
+func AddExcelCopySheetTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_copy_sheet",
 		mcp.WithDescription("Copy existing sheet to a new sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelCopySheetOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -77,5 +86,9 @@ func copySheet(fileAbsolutePath string, srcSheetName string, dstSheetName string
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Sheet [%s] copied to [%s].\n", html.EscapeString(srcSheetName), html.EscapeString(dstSheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelCopySheetOutput{
+		Backend:      workbook.GetBackendName(),
+		SrcSheetName: srcSheetName,
+		DstSheetName: dstSheetName,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -26,9 +25,20 @@ var excelCreateTableArgumentsSchema = z.Struct(z.Shape{
 	"tableName":        z.String().Required(),
 })
 
-func AddExcelCreateTableTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_create_table",
+type ExcelCreateTableOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Range     string `json:"range"`
+	TableName string `json:"tableName"`
+	WriteOutput
+}
+
+func AddExcelCreateTableTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_create_table",
 		mcp.WithDescription("Create a table in the Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelCreateTableOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -77,5 +87,10 @@ func createTable(fileAbsolutePath string, sheetName string, tableRange string, t
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Table [%s] created.\n", html.EscapeString(tableName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelCreateTableOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Range:     tableRange,
+		TableName: tableName,
+	}, result), nil
 }
@@ -8,7 +8,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -21,9 +20,18 @@ var excelCreateWorkbookArgumentsSchema = z.Struct(z.Shape{
 	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
 })
 
-func AddExcelCreateWorkbookTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_create_workbook",
+type ExcelCreateWorkbookOutput struct {
+	FileAbsolutePath string `json:"fileAbsolutePath"`
+	OLE              bool   `json:"ole" jsonschema:"true if the workbook was created in Excel via OLE, false if it was created by excelize"`
+	WriteOutput
+}
+
+func AddExcelCreateWorkbookTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_create_workbook",
 		mcp.WithDescription("Create a new workbook in Excel application and save it (Windows only)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelCreateWorkbookOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path where the new Excel file will be saved"),
@@ -47,7 +55,10 @@ func createWorkbook(fileAbsolutePath string) (*mcp.CallToolResult, error) {
 		if err := app.CreateWorkbook(fileAbsolutePath); err == nil {
 			result := "# Notice\n"
 			result += fmt.Sprintf("New workbook created and opened in Excel: %s\n", fileAbsolutePath)
-			return mcp.NewToolResultText(result), nil
+			return mcp.NewToolResultStructured(&ExcelCreateWorkbookOutput{
+				FileAbsolutePath: fileAbsolutePath,
+				OLE:              true,
+			}, result), nil
 		}
 	}
 
@@ -70,5 +81,8 @@ func createWorkbook(fileAbsolutePath string) (*mcp.CallToolResult, error) {
 
 	result := "# Notice\n"
 	result += fmt.Sprintf("New workbook created and saved to: %s\n", fileAbsolutePath)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelCreateWorkbookOutput{
+		FileAbsolutePath: fileAbsolutePath,
+		OLE:              false,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -17,6 +16,7 @@ type ExcelDeleteColumnsArguments struct {
 	SheetName        string `zog:"sheetName"`
 	Column           string `zog:"column"`
 	Count            int    `zog:"count"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelDeleteColumnsArgumentsSchema = z.Struct(z.Shape{
@@ -24,11 +24,23 @@ var excelDeleteColumnsArgumentsSchema = z.Struct(z.Shape{
 	"sheetName":        z.String().Required(),
 	"column":           z.String().Required(),
 	"count":            z.Int().GTE(1).Required(),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelDeleteColumnsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_delete_columns",
+type ExcelDeleteColumnsOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Column    string `json:"column"`
+	Count     int    `json:"count"`
+	WriteOutput
+}
+
+func AddExcelDeleteColumnsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_delete_columns",
 		mcp.WithDescription("Delete columns at the specified position"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelDeleteColumnsOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -45,6 +57,9 @@ func AddExcelDeleteColumnsTool(server *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("Number of columns to delete"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleDeleteColumns))
 }
 
@@ -53,6 +68,11 @@ func handleDeleteColumns(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	if issues := excelDeleteColumnsArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return deleteColumns(path, args.SheetName, args.Column, args.Count)
+		})
+	}
 	return deleteColumns(args.FileAbsolutePath, args.SheetName, args.Column, args.Count)
 }
 
@@ -79,5 +99,10 @@ func deleteColumns(fileAbsolutePath string, sheetName string, column string, cou
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Deleted %d column(s) starting at column %s in sheet [%s].\n", count, column, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelDeleteColumnsOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Column:    column,
+		Count:     count,
+	}, result), nil
 }
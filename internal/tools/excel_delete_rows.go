@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -17,6 +16,7 @@ type ExcelDeleteRowsArguments struct {
 	SheetName        string `zog:"sheetName"`
 	Row              int    `zog:"row"`
 	Count            int    `zog:"count"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelDeleteRowsArgumentsSchema = z.Struct(z.Shape{
@@ -24,11 +24,23 @@ var excelDeleteRowsArgumentsSchema = z.Struct(z.Shape{
 	"sheetName":        z.String().Required(),
 	"row":              z.Int().GTE(1).Required(),
 	"count":            z.Int().GTE(1).Required(),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelDeleteRowsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_delete_rows",
+type ExcelDeleteRowsOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Row       int    `json:"row"`
+	Count     int    `json:"count"`
+	WriteOutput
+}
+
+func AddExcelDeleteRowsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_delete_rows",
 		mcp.WithDescription("Delete rows at the specified position"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelDeleteRowsOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -45,6 +57,9 @@ func AddExcelDeleteRowsTool(server *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("Number of rows to delete"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleDeleteRows))
 }
 
@@ -53,6 +68,11 @@ func handleDeleteRows(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	if issues := excelDeleteRowsArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return deleteRows(path, args.SheetName, args.Row, args.Count)
+		})
+	}
 	return deleteRows(args.FileAbsolutePath, args.SheetName, args.Row, args.Count)
 }
 
@@ -79,5 +99,10 @@ func deleteRows(fileAbsolutePath string, sheetName string, row int, count int) (
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Deleted %d row(s) starting at row %d in sheet [%s].\n", count, row, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelDeleteRowsOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Row:       row,
+		Count:     count,
+	}, result), nil
 }
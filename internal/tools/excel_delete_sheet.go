@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -22,9 +21,18 @@ var excelDeleteSheetArgumentsSchema = z.Struct(z.Shape{
 	"sheetName":        z.String().Required(),
 })
 
-func AddExcelDeleteSheetTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_delete_sheet",
+type ExcelDeleteSheetOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	WriteOutput
+}
+
+func AddExcelDeleteSheetTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_delete_sheet",
 		mcp.WithDescription("Delete a sheet from the Excel file"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelDeleteSheetOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -61,5 +69,8 @@ func deleteSheet(fileAbsolutePath string, sheetName string) (*mcp.CallToolResult
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Sheet [%s] deleted.\n", html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelDeleteSheetOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+	}, result), nil
 }
@@ -6,7 +6,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	"github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -19,9 +18,12 @@ var excelDescribeSheetsArgumentsSchema = z.Struct(z.Shape{
 	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
 })
 
-func AddExcelDescribeSheetsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_describe_sheets",
+func AddExcelDescribeSheetsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_describe_sheets",
 		mcp.WithDescription("List all sheet information of specified Excel file"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[Response](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -35,7 +37,7 @@ func handleDescribeSheets(ctx context.Context, request mcp.CallToolRequest) (*mc
 	if len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
-	return describeSheets(args.FileAbsolutePath)
+	return describeSheets(ctx, args.FileAbsolutePath)
 }
 
 type Response struct {
@@ -60,7 +62,7 @@ type PivotTable struct {
 	Range string `json:"range"`
 }
 
-func describeSheets(fileAbsolutePath string) (*mcp.CallToolResult, error) {
+func describeSheets(ctx context.Context, fileAbsolutePath string) (*mcp.CallToolResult, error) {
 	config, issues := LoadConfig()
 	if issues != nil {
 		return imcp.NewToolResultZogIssueMap(issues), nil
@@ -109,11 +111,15 @@ func describeSheets(fileAbsolutePath string) (*mcp.CallToolResult, error) {
 			}
 		}
 		var pagingRanges []string
-		strategy, err := sheet.GetPagingStrategy(config.EXCEL_MCP_PAGING_CELLS_LIMIT)
+		// The paging ranges are those of excel_read_sheet with the default output format
+		strategy, err := newPagingStrategy(ctx, config, sheet, readSheetOptions{OutputFormat: OutputFormatHTML})
 		if err == nil {
 			pagingService := excel.NewPagingRangeService(strategy)
 			pagingRanges = pagingService.GetPagingRanges()
 		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		worksheets[i] = Worksheet{
 			Name:         name,
 			UsedRange:    usedRange,
@@ -131,5 +137,5 @@ func describeSheets(fileAbsolutePath string) (*mcp.CallToolResult, error) {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	return mcp.NewToolResultStructured(&response, string(jsonBytes)), nil
 }
@@ -5,12 +5,12 @@ import (
 	"encoding/csv"
 	"fmt"
 	"html"
+	"io"
 	"os"
 	"path/filepath"
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 	"github.com/xuri/excelize/v2"
@@ -32,9 +32,19 @@ var excelExportCsvArgumentsSchema = z.Struct(z.Shape{
 	"delimiter":        z.String().Default(","),
 })
 
-func AddExcelExportCsvTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_export_csv",
+type ExcelExportCsvOutput struct {
+	Backend      string `json:"backend"`
+	SheetName    string `json:"sheetName"`
+	OutputPath   string `json:"outputPath"`
+	RowsExported int    `json:"rowsExported"`
+}
+
+func AddExcelExportCsvTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessExport, mcp.NewTool("excel_export_csv",
 		mcp.WithDescription("Export an Excel sheet to a CSV file"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelExportCsvOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -61,10 +71,10 @@ func handleExportCsv(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	if issues := excelExportCsvArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
-	return exportCsv(args.FileAbsolutePath, args.SheetName, args.OutputPath, args.Range, args.Delimiter)
+	return exportCsv(ctx, args.FileAbsolutePath, args.SheetName, args.OutputPath, args.Range, args.Delimiter)
 }
 
-func exportCsv(fileAbsolutePath string, sheetName string, outputPath string, rangeStr string, delimiter string) (*mcp.CallToolResult, error) {
+func exportCsv(ctx context.Context, fileAbsolutePath string, sheetName string, outputPath string, rangeStr string, delimiter string) (*mcp.CallToolResult, error) {
 	workbook, release, err := excel.OpenFile(fileAbsolutePath)
 	if err != nil {
 		return nil, err
@@ -102,42 +112,52 @@ func exportCsv(fileAbsolutePath string, sheetName string, outputPath string, ran
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Create output file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	writer.Comma = delimRune
-
+	// Write to a temporary file renamed over the output on success, so that
+	// neither a failure nor a cancellation leaves a truncated CSV file behind
 	rowCount := 0
-	for row := startRow; row <= endRow; row++ {
-		record := make([]string, 0, endCol-startCol+1)
-		for col := startCol; col <= endCol; col++ {
-			cellName, err := excelize.CoordinatesToCellName(col, row)
-			if err != nil {
-				return nil, err
+	err = excel.WriteFileAtomically(outputPath, func(file io.Writer) error {
+		writer := csv.NewWriter(file)
+		writer.Comma = delimRune
+		for row := startRow; row <= endRow; row++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			reportProgress(ctx, row-startRow, endRow-startRow+1, "exporting rows")
+			record := make([]string, 0, endCol-startCol+1)
+			for col := startCol; col <= endCol; col++ {
+				cellName, err := excelize.CoordinatesToCellName(col, row)
+				if err != nil {
+					return err
+				}
+				val, err := worksheet.GetValue(cellName)
+				if err != nil {
+					return err
+				}
+				record = append(record, val)
 			}
-			val, err := worksheet.GetValue(cellName)
-			if err != nil {
-				return nil, err
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
 			}
-			record = append(record, val)
+			rowCount++
 		}
-		if err := writer.Write(record); err != nil {
-			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("CSV write error: %w", err)
 		}
-		rowCount++
-	}
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return nil, fmt.Errorf("CSV write error: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	reportProgress(ctx, rowCount, endRow-startRow+1, "exporting rows")
 
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Exported %d rows from sheet [%s] to %s\n", rowCount, html.EscapeString(sheetName), outputPath)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelExportCsvOutput{
+		Backend:      workbook.GetBackendName(),
+		SheetName:    sheetName,
+		OutputPath:   outputPath,
+		RowsExported: rowCount,
+	}, result), nil
 }
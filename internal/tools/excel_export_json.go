@@ -10,7 +10,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 	"github.com/xuri/excelize/v2"
@@ -22,6 +21,7 @@ type ExcelExportJsonArguments struct {
 	OutputPath       string `zog:"outputPath"`
 	Range            string `zog:"range"`
 	HeaderRow        bool   `zog:"headerRow"`
+	ShowTypes        bool   `zog:"showTypes"`
 }
 
 var excelExportJsonArgumentsSchema = z.Struct(z.Shape{
@@ -30,11 +30,22 @@ var excelExportJsonArgumentsSchema = z.Struct(z.Shape{
 	"outputPath":       z.String().Test(AbsolutePathTest()).Required(),
 	"range":            z.String(),
 	"headerRow":        z.Bool().Default(true),
+	"showTypes":        z.Bool().Default(false),
 })
 
-func AddExcelExportJsonTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_export_json",
+type ExcelExportJsonOutput struct {
+	Backend      string `json:"backend"`
+	SheetName    string `json:"sheetName"`
+	OutputPath   string `json:"outputPath"`
+	RowsExported int    `json:"rowsExported"`
+}
+
+func AddExcelExportJsonTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessExport, mcp.NewTool("excel_export_json",
 		mcp.WithDescription("Export an Excel sheet to a JSON file"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelExportJsonOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -53,6 +64,9 @@ func AddExcelExportJsonTool(server *server.MCPServer) {
 		mcp.WithBoolean("headerRow",
 			mcp.Description("Use the first row as JSON keys (default: true)"),
 		),
+		mcp.WithBoolean("showTypes",
+			mcp.Description("Export each cell as an object of the raw value as a JSON number, boolean or string, the type, the number format and the formatted text instead of the formatted text (default: false)"),
+		),
 	), WithRecovery(handleExportJson))
 }
 
@@ -61,10 +75,10 @@ func handleExportJson(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	if issues := excelExportJsonArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
-	return exportJson(args.FileAbsolutePath, args.SheetName, args.OutputPath, args.Range, args.HeaderRow)
+	return exportJson(args.FileAbsolutePath, args.SheetName, args.OutputPath, args.Range, args.HeaderRow, args.ShowTypes)
 }
 
-func exportJson(fileAbsolutePath string, sheetName string, outputPath string, rangeStr string, headerRow bool) (*mcp.CallToolResult, error) {
+func exportJson(fileAbsolutePath string, sheetName string, outputPath string, rangeStr string, headerRow bool, showTypes bool) (*mcp.CallToolResult, error) {
 	workbook, release, err := excel.OpenFile(fileAbsolutePath)
 	if err != nil {
 		return nil, err
@@ -91,6 +105,20 @@ func exportJson(fileAbsolutePath string, sheetName string, outputPath string, ra
 		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
 	}
 
+	// readCell reads the value of a cell to export
+	readCell := func(cell string) (any, error) {
+		return worksheet.GetValue(cell)
+	}
+	if showTypes {
+		readCell = func(cell string) (any, error) {
+			info, err := worksheet.GetCellInfo(cell)
+			if err != nil {
+				return nil, err
+			}
+			return newTypedCell(info), nil
+		}
+	}
+
 	var result interface{}
 	rowCount := 0
 
@@ -118,7 +146,7 @@ func exportJson(fileAbsolutePath string, sheetName string, outputPath string, ra
 				if err != nil {
 					return nil, err
 				}
-				val, err := worksheet.GetValue(cellName)
+				val, err := readCell(cellName)
 				if err != nil {
 					return nil, err
 				}
@@ -133,15 +161,15 @@ func exportJson(fileAbsolutePath string, sheetName string, outputPath string, ra
 		result = objects
 	} else {
 		// Read all rows as arrays
-		var rows [][]string
+		var rows [][]any
 		for row := startRow; row <= endRow; row++ {
-			record := make([]string, 0, endCol-startCol+1)
+			record := make([]any, 0, endCol-startCol+1)
 			for col := startCol; col <= endCol; col++ {
 				cellName, err := excelize.CoordinatesToCellName(col, row)
 				if err != nil {
 					return nil, err
 				}
-				val, err := worksheet.GetValue(cellName)
+				val, err := readCell(cellName)
 				if err != nil {
 					return nil, err
 				}
@@ -170,5 +198,10 @@ func exportJson(fileAbsolutePath string, sheetName string, outputPath string, ra
 	resultText := "# Notice\n"
 	resultText += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	resultText += fmt.Sprintf("Exported %d rows from sheet [%s] to %s\n", rowCount, html.EscapeString(sheetName), outputPath)
-	return mcp.NewToolResultText(resultText), nil
+	return mcp.NewToolResultStructured(&ExcelExportJsonOutput{
+		Backend:      workbook.GetBackendName(),
+		SheetName:    sheetName,
+		OutputPath:   outputPath,
+		RowsExported: rowCount,
+	}, resultText), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -20,6 +19,7 @@ type ExcelFindReplaceArguments struct {
 	Range            string `zog:"range"`
 	MatchCase        bool   `zog:"matchCase"`
 	MatchEntireCell  bool   `zog:"matchEntireCell"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelFindReplaceArgumentsSchema = z.Struct(z.Shape{
@@ -30,11 +30,22 @@ var excelFindReplaceArgumentsSchema = z.Struct(z.Shape{
 	"range":            z.String(),
 	"matchCase":        z.Bool().Default(false),
 	"matchEntireCell":  z.Bool().Default(false),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelFindReplaceTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_find_replace",
+type ExcelFindReplaceOutput struct {
+	Backend      string `json:"backend"`
+	SheetName    string `json:"sheetName"`
+	Replacements *int   `json:"replacements,omitempty" jsonschema:"Number of replaced occurrences, absent if the backend does not report it"`
+	WriteOutput
+}
+
+func AddExcelFindReplaceTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_find_replace",
 		mcp.WithDescription("Find and replace values in the Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelFindReplaceOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -60,6 +71,9 @@ func AddExcelFindReplaceTool(server *server.MCPServer) {
 		mcp.WithBoolean("matchEntireCell",
 			mcp.Description("Match entire cell contents (default: false)"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleFindReplace))
 }
 
@@ -68,10 +82,15 @@ func handleFindReplace(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	if issues := excelFindReplaceArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
-	return findReplace(args.FileAbsolutePath, args.SheetName, args.Find, args.Replace, args.Range, args.MatchCase, args.MatchEntireCell)
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return findReplace(ctx, path, args.SheetName, args.Find, args.Replace, args.Range, args.MatchCase, args.MatchEntireCell)
+		})
+	}
+	return findReplace(ctx, args.FileAbsolutePath, args.SheetName, args.Find, args.Replace, args.Range, args.MatchCase, args.MatchEntireCell)
 }
 
-func findReplace(fileAbsolutePath string, sheetName string, find string, replace string, searchRange string, matchCase bool, matchEntireCell bool) (*mcp.CallToolResult, error) {
+func findReplace(ctx context.Context, fileAbsolutePath string, sheetName string, find string, replace string, searchRange string, matchCase bool, matchEntireCell bool) (*mcp.CallToolResult, error) {
 	workbook, release, err := excel.OpenFile(fileAbsolutePath)
 	if err != nil {
 		return nil, err
@@ -84,20 +103,28 @@ func findReplace(fileAbsolutePath string, sheetName string, find string, replace
 	}
 	defer worksheet.Release()
 
-	count, err := worksheet.FindReplace(searchRange, find, replace, matchCase, matchEntireCell)
+	count, err := worksheet.FindReplace(ctx, searchRange, find, replace, matchCase, matchEntireCell, func(current int, total int) {
+		reportProgress(ctx, current, total, "searching rows")
+	})
 	if err != nil {
+		// The workbook is not saved, so a cancelled call leaves the file untouched
 		return nil, err
 	}
 	if err := workbook.Save(); err != nil {
 		return nil, err
 	}
 
+	output := &ExcelFindReplaceOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+	}
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	if count >= 0 {
+		output.Replacements = &count
 		result += fmt.Sprintf("Replaced %d occurrence(s) of \"%s\" with \"%s\" in sheet [%s].\n", count, html.EscapeString(find), html.EscapeString(replace), html.EscapeString(sheetName))
 	} else {
 		result += fmt.Sprintf("Replaced \"%s\" with \"%s\" in sheet [%s].\n", html.EscapeString(find), html.EscapeString(replace), html.EscapeString(sheetName))
 	}
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(output, result), nil
 }
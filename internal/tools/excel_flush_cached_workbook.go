@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelFlushCachedWorkbookArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+}
+
+var excelFlushCachedWorkbookArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+})
+
+type ExcelFlushCachedWorkbookOutput struct {
+	CacheEnabled bool `json:"cacheEnabled"`
+	Flushed      bool `json:"flushed" jsonschema:"true if the workbook had unsaved changes which have been written to the file"`
+}
+
+func AddExcelFlushCachedWorkbookTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_flush_cached_workbook",
+		mcp.WithDescription("Write the changes of the Excel file held in the workbook cache of the server to the file. The workbook stays in the cache"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelFlushCachedWorkbookOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+	), WithRecovery(handleFlushCachedWorkbook))
+}
+
+func handleFlushCachedWorkbook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelFlushCachedWorkbookArguments{}
+	if issues := excelFlushCachedWorkbookArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	return flushCachedWorkbook(args.FileAbsolutePath)
+}
+
+func flushCachedWorkbook(fileAbsolutePath string) (*mcp.CallToolResult, error) {
+	output := &ExcelFlushCachedWorkbookOutput{CacheEnabled: excel.IsWorkbookCacheEnabled()}
+	result := "# Notice\n"
+	if !output.CacheEnabled {
+		result += "Workbook cache is disabled. Changes are written to the file immediately.\n"
+	} else if flushed, err := excel.FlushCachedWorkbook(fileAbsolutePath); errors.Is(err, excel.ErrWorkbookChangedOnDisk) {
+		return newWorkbookChangedOnDiskResult(err), nil
+	} else if err != nil {
+		return nil, err
+	} else if flushed {
+		output.Flushed = true
+		result += fmt.Sprintf("Wrote the changes of %s to the file.\n", fileAbsolutePath)
+	} else {
+		result += fmt.Sprintf("Workbook %s has no unsaved changes.\n", fileAbsolutePath)
+	}
+	return mcp.NewToolResultStructured(output, result), nil
+}
+
+// newWorkbookChangedOnDiskResult reports the unsaved changes of the workbook cache which conflict with the file on disk.
+func newWorkbookChangedOnDiskResult(err error) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("%s. The unsaved changes are kept in the cache. "+
+		"Call excel_close_cached_workbook with discardChanges to drop them and read the file again", err))
+}
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	"github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 	"github.com/xuri/excelize/v2"
@@ -18,6 +17,7 @@ type ExcelFormatRangeArguments struct {
 	SheetName        string               `zog:"sheetName"`
 	Range            string               `zog:"range"`
 	Styles           [][]*excel.CellStyle `zog:"styles"`
+	DryRun           bool                 `zog:"dryRun"`
 }
 
 var colorPattern, _ = regexp.Compile("^#[0-9A-Fa-f]{6}$")
@@ -48,15 +48,50 @@ var excelFormatRangeArgumentsSchema = z.Struct(z.Shape{
 				"color":   z.Slice(z.String().Match(colorPattern)).Default([]string{}),
 				"shading": z.Ptr(z.StringLike[excel.FillShading]().OneOf(excel.FillShadingValues())),
 			})),
+			"alignment": z.Ptr(z.Struct(z.Shape{
+				"horizontal":   z.Ptr(z.StringLike[excel.HorizontalAlignment]().OneOf(excel.HorizontalAlignmentValues())),
+				"vertical":     z.Ptr(z.StringLike[excel.VerticalAlignment]().OneOf(excel.VerticalAlignmentValues())),
+				"wrapText":     z.Ptr(z.Bool()),
+				"indent":       z.Ptr(z.Int().GTE(0).LTE(250)),
+				"textRotation": z.Ptr(z.Int().Test(textRotationTest())),
+				"shrinkToFit":  z.Ptr(z.Bool()),
+			})),
+			"protection": z.Ptr(z.Struct(z.Shape{
+				"locked": z.Ptr(z.Bool()),
+				"hidden": z.Ptr(z.Bool()),
+			})),
 			"numFmt":        z.Ptr(z.String()),
 			"decimalPlaces": z.Ptr(z.Int().GTE(0).LTE(30)),
 		}),
 		))).Required(),
+	"dryRun": z.Bool().Default(false),
 })
 
-func AddExcelFormatRangeTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_format_range",
+// textRotationTest accepts an angle from -90 to 90 degrees or the vertical text.
+func textRotationTest() z.Test[*int] {
+	return z.Test[*int]{
+		Func: func(textRotation *int, ctx z.Ctx) {
+			if (*textRotation < -90 || *textRotation > 90) && *textRotation != excel.TextRotationVertical {
+				ctx.AddIssue(ctx.Issue().SetMessage(fmt.Sprintf("textRotation must be from -90 to 90, or %d for vertical text", excel.TextRotationVertical)))
+			}
+		},
+	}
+}
+
+type ExcelFormatRangeOutput struct {
+	Backend        string `json:"backend"`
+	SheetName      string `json:"sheetName"`
+	Range          string `json:"range"`
+	CellsProcessed int    `json:"cellsProcessed"`
+	WriteOutput
+}
+
+func AddExcelFormatRangeTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_format_range",
 		mcp.WithDescription("Format cells in the Excel sheet with style information"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelFormatRangeOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -151,6 +186,41 @@ func AddExcelFormatRangeTool(server *server.MCPServer) {
 									},
 									"required": []string{"type", "pattern", "color"},
 								},
+								"alignment": map[string]any{
+									"type": "object",
+									"properties": map[string]any{
+										"horizontal": map[string]any{
+											"type": "string",
+											"enum": excel.HorizontalAlignmentValues(),
+										},
+										"vertical": map[string]any{
+											"type": "string",
+											"enum": excel.VerticalAlignmentValues(),
+										},
+										"wrapText": map[string]any{"type": "boolean"},
+										"indent": map[string]any{
+											"type":    "integer",
+											"minimum": 0,
+											"maximum": 250,
+										},
+										"textRotation": map[string]any{
+											"type":        "integer",
+											"description": fmt.Sprintf("Angle of the text from -90 to 90 degrees, or %d for vertical text", excel.TextRotationVertical),
+										},
+										"shrinkToFit": map[string]any{"type": "boolean"},
+									},
+								},
+								"protection": map[string]any{
+									"type":        "object",
+									"description": "Protection of the cell, which takes effect when the sheet is protected. A cell is locked and not hidden by default",
+									"properties": map[string]any{
+										"locked": map[string]any{"type": "boolean"},
+										"hidden": map[string]any{
+											"type":        "boolean",
+											"description": "Hide the formula of the cell",
+										},
+									},
+								},
 								"numFmt": map[string]any{
 									"type":        "string",
 									"description": "Custom number format string",
@@ -170,6 +240,9 @@ func AddExcelFormatRangeTool(server *server.MCPServer) {
 				},
 			}),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleFormatRange))
 }
 
@@ -179,6 +252,11 @@ func handleFormatRange(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	if len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return formatRange(path, args.SheetName, args.Range, args.Styles)
+		})
+	}
 	return formatRange(args.FileAbsolutePath, args.SheetName, args.Range, args.Styles)
 }
 
@@ -195,9 +273,8 @@ func formatRange(fileAbsolutePath string, sheetName string, rangeStr string, sty
 	}
 
 	// Check data consistency
-	rangeRowSize := endRow - startRow + 1
-	if len(styles) != rangeRowSize {
-		return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("number of style rows (%d) does not match range size (%d)", len(styles), rangeRowSize)), nil
+	if err := validateStylesSize(styles, startCol, startRow, endCol, endRow); err != nil {
+		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
 	}
 
 	// Get worksheet
@@ -208,23 +285,8 @@ func formatRange(fileAbsolutePath string, sheetName string, rangeStr string, sty
 	defer worksheet.Release()
 
 	// Apply styles to each cell
-	for i, styleRow := range styles {
-		rangeColumnSize := endCol - startCol + 1
-		if len(styleRow) != rangeColumnSize {
-			return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("number of style columns in row %d (%d) does not match range size (%d)", i, len(styleRow), rangeColumnSize)), nil
-		}
-
-		for j, style := range styleRow {
-			cell, err := excelize.CoordinatesToCellName(startCol+j, startRow+i)
-			if err != nil {
-				return nil, err
-			}
-			if style != nil {
-				if err := worksheet.SetCellStyle(cell, style); err != nil {
-					return nil, fmt.Errorf("failed to set style for cell %s: %w", cell, err)
-				}
-			}
-		}
+	if err := setStyles(worksheet, startCol, startRow, styles); err != nil {
+		return nil, err
 	}
 
 	if err := workbook.Save(); err != nil {
@@ -244,5 +306,43 @@ func formatRange(fileAbsolutePath string, sheetName string, rangeStr string, sty
 	html += "<h2>Notice</h2>\n"
 	html += "<p>Cell styles applied successfully.</p>\n"
 
-	return mcp.NewToolResultText(html), nil
+	return mcp.NewToolResultStructured(&ExcelFormatRangeOutput{
+		Backend:        workbook.GetBackendName(),
+		SheetName:      sheetName,
+		Range:          rangeStr,
+		CellsProcessed: (endRow - startRow + 1) * (endCol - startCol + 1),
+	}, html), nil
+}
+
+// validateStylesSize checks that the styles have the same size as the range.
+func validateStylesSize(styles [][]*excel.CellStyle, startCol int, startRow int, endCol int, endRow int) error {
+	rangeRowSize := endRow - startRow + 1
+	if len(styles) != rangeRowSize {
+		return fmt.Errorf("number of style rows (%d) does not match range size (%d)", len(styles), rangeRowSize)
+	}
+	rangeColumnSize := endCol - startCol + 1
+	for i, styleRow := range styles {
+		if len(styleRow) != rangeColumnSize {
+			return fmt.Errorf("number of style columns in row %d (%d) does not match range size (%d)", i, len(styleRow), rangeColumnSize)
+		}
+	}
+	return nil
+}
+
+// setStyles applies the styles starting at the cell. A nil style leaves the cell unchanged.
+func setStyles(worksheet excel.Worksheet, startCol int, startRow int, styles [][]*excel.CellStyle) error {
+	for i, styleRow := range styles {
+		for j, style := range styleRow {
+			cell, err := excelize.CoordinatesToCellName(startCol+j, startRow+i)
+			if err != nil {
+				return err
+			}
+			if style != nil {
+				if err := worksheet.SetCellStyle(cell, style); err != nil {
+					return fmt.Errorf("failed to set style for cell %s: %w", cell, err)
+				}
+			}
+		}
+	}
+	return nil
 }
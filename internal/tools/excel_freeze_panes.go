@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -24,9 +23,19 @@ var excelFreezePanesArgumentsSchema = z.Struct(z.Shape{
 	"cell":             z.String().Required(),
 })
 
-func AddExcelFreezePanesTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_freeze_panes",
+type ExcelFreezePanesOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Cell      string `json:"cell"`
+	WriteOutput
+}
+
+func AddExcelFreezePanesTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_freeze_panes",
 		mcp.WithDescription("Freeze rows and columns at the specified cell"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelFreezePanesOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -73,5 +82,9 @@ func freezePanes(fileAbsolutePath string, sheetName string, cell string) (*mcp.C
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Panes frozen at %s in sheet [%s].\n", cell, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelFreezePanesOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Cell:      cell,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -22,9 +21,18 @@ var excelGetCommentsArgumentsSchema = z.Struct(z.Shape{
 	"sheetName":        z.String().Required(),
 })
 
-func AddExcelGetCommentsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_get_comments",
+type ExcelGetCommentsOutput struct {
+	Backend   string          `json:"backend"`
+	SheetName string          `json:"sheetName"`
+	Comments  []excel.Comment `json:"comments"`
+}
+
+func AddExcelGetCommentsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_get_comments",
 		mcp.WithDescription("Get all comments from a sheet in the Excel file"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelGetCommentsOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -71,5 +79,9 @@ func getComments(fileAbsolutePath string, sheetName string) (*mcp.CallToolResult
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Found %d comment(s) in sheet [%s].\n\n", len(comments), sheetName)
 	result += string(jsonData) + "\n"
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelGetCommentsOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Comments:  comments,
+	}, result), nil
 }
@@ -10,7 +10,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 	"github.com/xuri/excelize/v2"
@@ -23,6 +22,7 @@ type ExcelImportCsvArguments struct {
 	StartCell        string `zog:"startCell"`
 	Delimiter        string `zog:"delimiter"`
 	NewSheet         bool   `zog:"newSheet"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelImportCsvArgumentsSchema = z.Struct(z.Shape{
@@ -32,11 +32,24 @@ var excelImportCsvArgumentsSchema = z.Struct(z.Shape{
 	"startCell":        z.String().Default("A1"),
 	"delimiter":        z.String().Default(","),
 	"newSheet":         z.Bool().Default(false),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelImportCsvTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_import_csv",
+type ExcelImportCsvOutput struct {
+	Backend     string `json:"backend"`
+	SheetName   string `json:"sheetName"`
+	SourcePath  string `json:"sourcePath"`
+	StartCell   string `json:"startCell"`
+	RowsWritten int    `json:"rowsWritten"`
+	WriteOutput
+}
+
+func AddExcelImportCsvTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_import_csv",
 		mcp.WithDescription("Import a CSV file into an Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelImportCsvOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -58,6 +71,9 @@ func AddExcelImportCsvTool(server *server.MCPServer) {
 		mcp.WithBoolean("newSheet",
 			mcp.Description("Create a new sheet if true (default: false)"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleImportCsv))
 }
 
@@ -66,6 +82,11 @@ func handleImportCsv(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	if issues := excelImportCsvArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return importCsv(path, args.SheetName, args.CsvPath, args.StartCell, args.Delimiter, args.NewSheet)
+		})
+	}
 	return importCsv(args.FileAbsolutePath, args.SheetName, args.CsvPath, args.StartCell, args.Delimiter, args.NewSheet)
 }
 
@@ -145,5 +166,11 @@ func importCsv(fileAbsolutePath string, sheetName string, csvPath string, startC
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Imported %d rows from %s into sheet [%s] starting at %s\n", len(records), csvPath, html.EscapeString(sheetName), startCell)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelImportCsvOutput{
+		Backend:     workbook.GetBackendName(),
+		SheetName:   sheetName,
+		SourcePath:  csvPath,
+		StartCell:   startCell,
+		RowsWritten: len(records),
+	}, result), nil
 }
@@ -10,7 +10,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 	"github.com/xuri/excelize/v2"
@@ -23,6 +22,7 @@ type ExcelImportJsonArguments struct {
 	StartCell        string `zog:"startCell"`
 	HeaderRow        bool   `zog:"headerRow"`
 	NewSheet         bool   `zog:"newSheet"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelImportJsonArgumentsSchema = z.Struct(z.Shape{
@@ -32,11 +32,24 @@ var excelImportJsonArgumentsSchema = z.Struct(z.Shape{
 	"startCell":        z.String().Default("A1"),
 	"headerRow":        z.Bool().Default(true),
 	"newSheet":         z.Bool().Default(false),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelImportJsonTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_import_json",
+type ExcelImportJsonOutput struct {
+	Backend     string `json:"backend"`
+	SheetName   string `json:"sheetName"`
+	SourcePath  string `json:"sourcePath"`
+	StartCell   string `json:"startCell"`
+	RowsWritten int    `json:"rowsWritten"`
+	WriteOutput
+}
+
+func AddExcelImportJsonTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_import_json",
 		mcp.WithDescription("Import a JSON file into an Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelImportJsonOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -58,6 +71,9 @@ func AddExcelImportJsonTool(server *server.MCPServer) {
 		mcp.WithBoolean("newSheet",
 			mcp.Description("Create a new sheet if true (default: false)"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleImportJson))
 }
 
@@ -66,10 +82,15 @@ func handleImportJson(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	if issues := excelImportJsonArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
-	return importJson(args.FileAbsolutePath, args.SheetName, args.JsonPath, args.StartCell, args.HeaderRow, args.NewSheet)
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return importJson(ctx, path, args.SheetName, args.JsonPath, args.StartCell, args.HeaderRow, args.NewSheet)
+		})
+	}
+	return importJson(ctx, args.FileAbsolutePath, args.SheetName, args.JsonPath, args.StartCell, args.HeaderRow, args.NewSheet)
 }
 
-func importJson(fileAbsolutePath string, sheetName string, jsonPath string, startCell string, headerRow bool, newSheet bool) (*mcp.CallToolResult, error) {
+func importJson(ctx context.Context, fileAbsolutePath string, sheetName string, jsonPath string, startCell string, headerRow bool, newSheet bool) (*mcp.CallToolResult, error) {
 	// Read JSON file
 	jsonData, err := os.ReadFile(jsonPath)
 	if err != nil {
@@ -79,19 +100,19 @@ func importJson(fileAbsolutePath string, sheetName string, jsonPath string, star
 	// Try to parse as array of objects first
 	var objects []map[string]interface{}
 	if err := json.Unmarshal(jsonData, &objects); err == nil && len(objects) > 0 {
-		return importJsonObjects(fileAbsolutePath, sheetName, startCell, headerRow, newSheet, objects, jsonPath)
+		return importJsonObjects(ctx, fileAbsolutePath, sheetName, startCell, headerRow, newSheet, objects, jsonPath)
 	}
 
 	// Try to parse as array of arrays
 	var arrays [][]interface{}
 	if err := json.Unmarshal(jsonData, &arrays); err == nil {
-		return importJsonArrays(fileAbsolutePath, sheetName, startCell, newSheet, arrays, jsonPath)
+		return importJsonArrays(ctx, fileAbsolutePath, sheetName, startCell, newSheet, arrays, jsonPath)
 	}
 
 	return imcp.NewToolResultInvalidArgumentError("JSON must be an array of objects or an array of arrays"), nil
 }
 
-func importJsonObjects(fileAbsolutePath string, sheetName string, startCell string, headerRow bool, newSheet bool, objects []map[string]interface{}, jsonPath string) (*mcp.CallToolResult, error) {
+func importJsonObjects(ctx context.Context, fileAbsolutePath string, sheetName string, startCell string, headerRow bool, newSheet bool, objects []map[string]interface{}, jsonPath string) (*mcp.CallToolResult, error) {
 	// Parse start cell
 	startCol, startRow, err := excelize.CellNameToCoordinates(startCell)
 	if err != nil {
@@ -148,7 +169,12 @@ func importJsonObjects(fileAbsolutePath string, sheetName string, startCell stri
 	}
 
 	// Write data rows
-	for _, obj := range objects {
+	for i, obj := range objects {
+		// Return before saving so that a cancelled import leaves the file untouched
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reportProgress(ctx, i, len(objects), "importing rows")
 		for j, key := range keys {
 			cellName, err := excelize.CoordinatesToCellName(startCol+j, currentRow)
 			if err != nil {
@@ -165,6 +191,7 @@ func importJsonObjects(fileAbsolutePath string, sheetName string, startCell stri
 		currentRow++
 		rowCount++
 	}
+	reportProgress(ctx, len(objects), len(objects), "importing rows")
 
 	if err := workbook.Save(); err != nil {
 		return nil, err
@@ -173,10 +200,16 @@ func importJsonObjects(fileAbsolutePath string, sheetName string, startCell stri
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Imported %d rows from %s into sheet [%s] starting at %s\n", rowCount, jsonPath, html.EscapeString(sheetName), startCell)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelImportJsonOutput{
+		Backend:     workbook.GetBackendName(),
+		SheetName:   sheetName,
+		SourcePath:  jsonPath,
+		StartCell:   startCell,
+		RowsWritten: rowCount,
+	}, result), nil
 }
 
-func importJsonArrays(fileAbsolutePath string, sheetName string, startCell string, newSheet bool, arrays [][]interface{}, jsonPath string) (*mcp.CallToolResult, error) {
+func importJsonArrays(ctx context.Context, fileAbsolutePath string, sheetName string, startCell string, newSheet bool, arrays [][]interface{}, jsonPath string) (*mcp.CallToolResult, error) {
 	// Parse start cell
 	startCol, startRow, err := excelize.CellNameToCoordinates(startCell)
 	if err != nil {
@@ -203,6 +236,11 @@ func importJsonArrays(fileAbsolutePath string, sheetName string, startCell strin
 	defer worksheet.Release()
 
 	for i, row := range arrays {
+		// Return before saving so that a cancelled import leaves the file untouched
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reportProgress(ctx, i, len(arrays), "importing rows")
 		for j, val := range row {
 			cellName, err := excelize.CoordinatesToCellName(startCol+j, startRow+i)
 			if err != nil {
@@ -216,6 +254,7 @@ func importJsonArrays(fileAbsolutePath string, sheetName string, startCell strin
 			}
 		}
 	}
+	reportProgress(ctx, len(arrays), len(arrays), "importing rows")
 
 	if err := workbook.Save(); err != nil {
 		return nil, err
@@ -224,5 +263,11 @@ func importJsonArrays(fileAbsolutePath string, sheetName string, startCell strin
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Imported %d rows from %s into sheet [%s] starting at %s\n", len(arrays), jsonPath, html.EscapeString(sheetName), startCell)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelImportJsonOutput{
+		Backend:     workbook.GetBackendName(),
+		SheetName:   sheetName,
+		SourcePath:  jsonPath,
+		StartCell:   startCell,
+		RowsWritten: len(arrays),
+	}, result), nil
 }
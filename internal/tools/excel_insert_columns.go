@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -17,6 +16,7 @@ type ExcelInsertColumnsArguments struct {
 	SheetName        string `zog:"sheetName"`
 	Column           string `zog:"column"`
 	Count            int    `zog:"count"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelInsertColumnsArgumentsSchema = z.Struct(z.Shape{
@@ -24,11 +24,23 @@ var excelInsertColumnsArgumentsSchema = z.Struct(z.Shape{
 	"sheetName":        z.String().Required(),
 	"column":           z.String().Required(),
 	"count":            z.Int().GTE(1).Required(),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelInsertColumnsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_insert_columns",
+type ExcelInsertColumnsOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Column    string `json:"column"`
+	Count     int    `json:"count"`
+	WriteOutput
+}
+
+func AddExcelInsertColumnsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_insert_columns",
 		mcp.WithDescription("Insert empty columns at the specified position"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelInsertColumnsOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -45,6 +57,9 @@ func AddExcelInsertColumnsTool(server *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("Number of columns to insert"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleInsertColumns))
 }
 
@@ -53,6 +68,11 @@ func handleInsertColumns(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	if issues := excelInsertColumnsArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return insertColumns(path, args.SheetName, args.Column, args.Count)
+		})
+	}
 	return insertColumns(args.FileAbsolutePath, args.SheetName, args.Column, args.Count)
 }
 
@@ -79,5 +99,10 @@ func insertColumns(fileAbsolutePath string, sheetName string, column string, cou
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Inserted %d column(s) at column %s in sheet [%s].\n", count, column, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelInsertColumnsOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Column:    column,
+		Count:     count,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -17,6 +16,7 @@ type ExcelInsertRowsArguments struct {
 	SheetName        string `zog:"sheetName"`
 	Row              int    `zog:"row"`
 	Count            int    `zog:"count"`
+	DryRun           bool   `zog:"dryRun"`
 }
 
 var excelInsertRowsArgumentsSchema = z.Struct(z.Shape{
@@ -24,11 +24,23 @@ var excelInsertRowsArgumentsSchema = z.Struct(z.Shape{
 	"sheetName":        z.String().Required(),
 	"row":              z.Int().GTE(1).Required(),
 	"count":            z.Int().GTE(1).Required(),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelInsertRowsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_insert_rows",
+type ExcelInsertRowsOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Row       int    `json:"row"`
+	Count     int    `json:"count"`
+	WriteOutput
+}
+
+func AddExcelInsertRowsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_insert_rows",
 		mcp.WithDescription("Insert empty rows at the specified position"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelInsertRowsOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -45,6 +57,9 @@ func AddExcelInsertRowsTool(server *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("Number of rows to insert"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleInsertRows))
 }
 
@@ -53,6 +68,11 @@ func handleInsertRows(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	if issues := excelInsertRowsArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return insertRows(path, args.SheetName, args.Row, args.Count)
+		})
+	}
 	return insertRows(args.FileAbsolutePath, args.SheetName, args.Row, args.Count)
 }
 
@@ -79,5 +99,10 @@ func insertRows(fileAbsolutePath string, sheetName string, row int, count int) (
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Inserted %d row(s) at row %d in sheet [%s].\n", count, row, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelInsertRowsOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Row:       row,
+		Count:     count,
+	}, result), nil
 }
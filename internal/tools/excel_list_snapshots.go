@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelListSnapshotsArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+}
+
+var excelListSnapshotsArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+})
+
+type ExcelListSnapshotsOutput struct {
+	Snapshots []Snapshot `json:"snapshots" jsonschema:"Snapshots from the newest to the oldest"`
+}
+
+func AddExcelListSnapshotsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_list_snapshots",
+		mcp.WithDescription("List the snapshots of the Excel file taken before each modifying tool call, newest first. Each snapshot shows the tool call which it was taken before"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelListSnapshotsOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+	), WithRecovery(handleListSnapshots))
+}
+
+func handleListSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelListSnapshotsArguments{}
+	if issues := excelListSnapshotsArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	return listSnapshots(args.FileAbsolutePath)
+}
+
+func listSnapshots(fileAbsolutePath string) (*mcp.CallToolResult, error) {
+	config, issues := LoadConfig()
+	if issues != nil {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	snapshots, err := newSnapshotStore(fileAbsolutePath, config).list()
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	result := "# Notice\n"
+	result += fmt.Sprintf("Found %d snapshot(s) of %s.\n", len(snapshots), fileAbsolutePath)
+	if len(snapshots) > 0 {
+		result += "Use excel_undo to revert the latest tool call, or excel_restore_snapshot with an id to go back to the state before that call.\n"
+	}
+	result += "\n" + string(jsonData) + "\n"
+	return mcp.NewToolResultStructured(&ExcelListSnapshotsOutput{
+		Snapshots: snapshots,
+	}, result), nil
+}
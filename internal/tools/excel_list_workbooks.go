@@ -6,16 +6,22 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 )
 
-func AddExcelListWorkbooksTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_list_workbooks",
+func AddExcelListWorkbooksTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_list_workbooks",
 		mcp.WithDescription("List all workbooks currently open in Excel (Windows only)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelListWorkbooksOutput](),
 	), WithRecovery(handleListWorkbooks))
 }
 
+type ExcelListWorkbooksOutput struct {
+	Workbooks []excel.WorkbookInfo `json:"workbooks"`
+}
+
 func handleListWorkbooks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return listWorkbooks()
 }
@@ -42,5 +48,7 @@ func listWorkbooks() (*mcp.CallToolResult, error) {
 	result += "```json\n"
 	result += string(jsonData) + "\n"
 	result += "```\n"
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelListWorkbooksOutput{
+		Workbooks: workbooks,
+	}, result), nil
 }
@@ -8,7 +8,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -21,9 +20,17 @@ var excelOpenWorkbookArgumentsSchema = z.Struct(z.Shape{
 	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
 })
 
-func AddExcelOpenWorkbookTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_open_workbook",
+type ExcelOpenWorkbookOutput struct {
+	FileAbsolutePath string `json:"fileAbsolutePath"`
+	OLE              bool   `json:"ole" jsonschema:"true if the workbook was opened via OLE, false if it was opened with the OS shell"`
+}
+
+func AddExcelOpenWorkbookTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_open_workbook",
 		mcp.WithDescription("Open a workbook file in Excel application (Windows only)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelOpenWorkbookOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file to open"),
@@ -47,7 +54,10 @@ func openWorkbook(fileAbsolutePath string) (*mcp.CallToolResult, error) {
 		if err := app.OpenWorkbook(fileAbsolutePath); err == nil {
 			result := "# Notice\n"
 			result += fmt.Sprintf("Workbook opened in Excel via OLE: %s\n", fileAbsolutePath)
-			return mcp.NewToolResultText(result), nil
+			return mcp.NewToolResultStructured(&ExcelOpenWorkbookOutput{
+				FileAbsolutePath: fileAbsolutePath,
+				OLE:              true,
+			}, result), nil
 		}
 	}
 
@@ -59,7 +69,10 @@ func openWorkbook(fileAbsolutePath string) (*mcp.CallToolResult, error) {
 		}
 		result := "# Notice\n"
 		result += fmt.Sprintf("Workbook opened in Excel: %s\n", fileAbsolutePath)
-		return mcp.NewToolResultText(result), nil
+		return mcp.NewToolResultStructured(&ExcelOpenWorkbookOutput{
+			FileAbsolutePath: fileAbsolutePath,
+			OLE:              false,
+		}, result), nil
 	}
 
 	return nil, fmt.Errorf("opening workbooks in Excel is only supported on Windows")
@@ -0,0 +1,430 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+// ExcelReadTarget is a range to read: a range of a sheet, a defined name or a table name.
+type ExcelReadTarget struct {
+	SheetName string `zog:"sheetName" json:"sheetName,omitempty"`
+	Range     string `zog:"range" json:"range"`
+}
+
+type ExcelReadRangesArguments struct {
+	FileAbsolutePath string            `zog:"fileAbsolutePath"`
+	Targets          []ExcelReadTarget `zog:"targets"`
+	ShowFormula      bool              `zog:"showFormula"`
+	ShowStyle        bool              `zog:"showStyle"`
+	ShowTypes        bool              `zog:"showTypes"`
+	OutputFormat     string            `zog:"outputFormat"`
+	JsonLayout       string            `zog:"jsonLayout"`
+}
+
+var excelReadRangesArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+	"targets": z.Slice(z.Struct(z.Shape{
+		"sheetName": z.String(),
+		"range":     z.String().Required(),
+	})).Min(1).Required(),
+	"showFormula":  z.Bool().Default(false),
+	"showStyle":    z.Bool().Default(false),
+	"showTypes":    z.Bool().Default(false),
+	"outputFormat": z.String().OneOf(outputFormats).Default(OutputFormatHTML),
+	"jsonLayout":   z.String().OneOf(jsonLayouts).Default(JsonLayoutRows),
+})
+
+type ExcelReadRangesOutput struct {
+	Backend string                 `json:"backend"`
+	Blocks  []ExcelReadRangesBlock `json:"blocks"`
+	// NextTargets is set when the targets exceed the paging limit
+	NextTargets []ExcelReadTarget `json:"nextTargets,omitempty" jsonschema:"Targets to read next, absent if all the targets were read"`
+}
+
+type ExcelReadRangesBlock struct {
+	Target    string `json:"target"`
+	SheetName string `json:"sheetName"`
+	Range     string `json:"range"`
+}
+
+func AddExcelReadRangesTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_read_ranges",
+		mcp.WithDescription("Read several ranges, possibly on different sheets, from an Excel file in one call. Each range is returned as a labelled block, and the paging limit applies to the total number of cells."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelReadRangesOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+		mcp.WithArray("targets",
+			mcp.Required(),
+			mcp.Description("Ranges to read in order. \"range\" is a range of cells (e.g., \"A1:C10\" with \"sheetName\", or \"Sheet1!A1:C10\"), a defined name or a table name. Ranges are clipped to the used range of the sheet"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"sheetName": map[string]any{
+						"type":        "string",
+						"description": "Sheet name, required for a range of cells without a sheet name",
+					},
+					"range": map[string]any{
+						"type":        "string",
+						"description": "Range of cells, defined name or table name",
+					},
+				},
+				"required": []string{"range"},
+			}),
+		),
+		mcp.WithBoolean("showFormula",
+			mcp.Description("Show formula instead of value"),
+		),
+		mcp.WithBoolean("showStyle",
+			mcp.Description("Show style information for cells. Not supported by the markdown and csv output formats"),
+		),
+		mcp.WithBoolean("showTypes",
+			mcp.Description("Show the type, the raw value and the number format of each cell. Not supported by the markdown and csv output formats"),
+		),
+		mcp.WithString("outputFormat",
+			mcp.Description("Format of the cells: html, markdown, csv or json (default: html)"),
+			mcp.Enum(outputFormats...),
+		),
+		mcp.WithString("jsonLayout",
+			mcp.Description("Layout of the json output format: rows (array of row arrays) or records (objects keyed by the first row of each range) (default: rows)"),
+			mcp.Enum(jsonLayouts...),
+		),
+	), WithRecovery(handleReadRanges))
+}
+
+func handleReadRanges(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelReadRangesArguments{}
+	if issues := excelReadRangesArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	return readRanges(ctx, args.FileAbsolutePath, args.Targets, readSheetOptions{
+		ShowFormula:  args.ShowFormula,
+		ShowStyle:    args.ShowStyle,
+		ShowTypes:    args.ShowTypes,
+		OutputFormat: args.OutputFormat,
+		JsonLayout:   args.JsonLayout,
+		Sparse:       SparseModeOff,
+	})
+}
+
+// readTarget is a target resolved into a range of a sheet.
+type readTarget struct {
+	// label is the name of the target, the defined name or the table name if it is given so
+	label     string
+	sheetName string
+	cellRange string
+}
+
+// readBlock is a part of a target which fits into the paging limit.
+type readBlock struct {
+	target   readTarget
+	startCol int
+	startRow int
+	endCol   int
+	endRow   int
+}
+
+func (b readBlock) cellRange() string {
+	return excel.RangeName(b.startCol, b.startRow, b.endCol, b.endRow)
+}
+
+func readRanges(ctx context.Context, fileAbsolutePath string, targets []ExcelReadTarget, options readSheetOptions) (*mcp.CallToolResult, error) {
+	config, issues := LoadConfig()
+	if issues != nil {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	if err := options.validate(); err != nil {
+		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
+	}
+
+	workbook, release, err := excel.OpenFile(fileAbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	definedNames, err := workbook.GetDefinedNames()
+	if err != nil {
+		return nil, err
+	}
+	resolved := make([]readTarget, len(targets))
+	for i, target := range targets {
+		resolved[i], err = resolveReadTarget(workbook, definedNames, target)
+		if err == nil {
+			resolved[i].cellRange, err = clipToUsedRange(workbook, resolved[i].sheetName, resolved[i].cellRange)
+		}
+		if err != nil {
+			return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("targets[%d]: %s", i, err)), nil
+		}
+	}
+	blocks, nextTargets, err := splitReadTargets(resolved, config.EXCEL_MCP_PAGING_CELLS_LIMIT)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := workbook.GetBackendName()
+	structured := &ExcelReadRangesOutput{Backend: backend, NextTargets: nextTargets}
+	var contents []mcp.Content
+	var jsonBlocks []jsonBlockOutput
+	for _, block := range blocks {
+		table, err := readBlockTable(ctx, workbook, block, options)
+		if err != nil {
+			return nil, err
+		}
+		output := sheetOutput{
+			title:      block.target.label,
+			backend:    backend,
+			sheetName:  block.target.sheetName,
+			rangeLabel: "read range",
+			cellRange:  block.cellRange(),
+		}
+		structured.Blocks = append(structured.Blocks, ExcelReadRangesBlock{
+			Target:    block.target.label,
+			SheetName: block.target.sheetName,
+			Range:     block.cellRange(),
+		})
+		if options.OutputFormat == OutputFormatJSON {
+			jsonBlocks = append(jsonBlocks, jsonBlockOutput{Target: block.target.label, jsonTableOutput: newJsonTableOutput(options.JsonLayout, table, output)})
+			continue
+		}
+		result, err := renderSheetOutput(options.OutputFormat, options.JsonLayout, table, output)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, result.Content...)
+	}
+
+	var notices []string
+	if len(nextTargets) > 0 {
+		notices = []string{
+			fmt.Sprintf("The targets exceed the paging limit of %d cells.", config.EXCEL_MCP_PAGING_CELLS_LIMIT),
+			"To read the rest, you should specify 'targets' argument as follows.",
+		}
+	}
+	nextTargetsJSON, err := json.Marshal(nextTargets)
+	if err != nil {
+		return nil, err
+	}
+	switch options.OutputFormat {
+	case OutputFormatJSON:
+		data, err := json.Marshal(jsonRangesOutput{Blocks: jsonBlocks, NextTargets: nextTargets, Notices: notices})
+		if err != nil {
+			return nil, err
+		}
+		contents = []mcp.Content{mcp.NewTextContent(string(data))}
+	case OutputFormatMarkdown:
+		if len(notices) > 0 {
+			contents = append(contents, mcp.NewTextContent(fmt.Sprintf("## Notice\n%s\n`%s`\n", strings.Join(notices, "\n"), nextTargetsJSON)))
+		}
+	case OutputFormatCSV:
+		if len(notices) > 0 {
+			contents = append(contents, mcp.NewTextContent(fmt.Sprintf("# Notice\n%s\nnext targets: %s\n", strings.Join(notices[:1], "\n"), nextTargetsJSON)))
+		}
+	default:
+		if len(notices) > 0 {
+			contents = append(contents, mcp.NewTextContent(fmt.Sprintf("<h2>Notice</h2>\n<p>%s</p>\n<code>%s</code>\n", strings.Join(notices, "</p>\n<p>"), htmlTextEscaper.Replace(string(nextTargetsJSON)))))
+		}
+	}
+	return &mcp.CallToolResult{Content: contents, StructuredContent: structured}, nil
+}
+
+// jsonBlockOutput is a block of the json output format of excel_read_ranges.
+type jsonBlockOutput struct {
+	Target string `json:"target"`
+	jsonTableOutput
+}
+
+type jsonRangesOutput struct {
+	Blocks      []jsonBlockOutput `json:"blocks"`
+	NextTargets []ExcelReadTarget `json:"nextTargets,omitempty"`
+	Notices     []string          `json:"notices,omitempty"`
+}
+
+func readBlockTable(ctx context.Context, workbook excel.Excel, block readBlock, options readSheetOptions) (*tableData, error) {
+	worksheet, err := workbook.FindSheet(block.target.sheetName)
+	if err != nil {
+		return nil, err
+	}
+	defer worksheet.Release()
+	return readSheetTable(ctx, worksheet, block.cellRange(), options)
+}
+
+// resolveReadTarget resolves the target into a range of a sheet.
+// The range of the target is a range of cells, a defined name or a table name in this order.
+func resolveReadTarget(workbook excel.Excel, definedNames []excel.DefinedName, target ExcelReadTarget) (readTarget, error) {
+	sheetName, cellRange := splitSheetReference(target.Range)
+	if sheetName != "" {
+		if target.SheetName != "" && target.SheetName != sheetName {
+			return readTarget{}, fmt.Errorf("sheet name %s does not match the sheet of range %s", target.SheetName, target.Range)
+		}
+	} else {
+		sheetName = target.SheetName
+	}
+	if _, _, _, _, err := excel.ParseRange(cellRange); err == nil {
+		if sheetName == "" {
+			return readTarget{}, fmt.Errorf("sheetName is required for range %s", target.Range)
+		}
+		cellRange = excel.NormalizeRange(cellRange)
+		return readTarget{label: referenceLabel(sheetName, cellRange), sheetName: sheetName, cellRange: cellRange}, nil
+	}
+
+	if refersTo, ok := findDefinedName(definedNames, cellRange, sheetName); ok {
+		refSheet, refRange := splitSheetReference(strings.TrimPrefix(refersTo, "="))
+		if strings.Contains(refRange, ",") {
+			return readTarget{}, fmt.Errorf("defined name %s refers to several areas: %s", cellRange, refersTo)
+		}
+		if _, _, _, _, err := excel.ParseRange(refRange); err != nil || refSheet == "" {
+			return readTarget{}, fmt.Errorf("defined name %s does not refer to a range of cells: %s", cellRange, refersTo)
+		}
+		refRange = excel.NormalizeRange(refRange)
+		return readTarget{label: fmt.Sprintf("%s (%s)", cellRange, referenceLabel(refSheet, refRange)), sheetName: refSheet, cellRange: refRange}, nil
+	}
+
+	worksheets, err := workbook.GetSheets()
+	if err != nil {
+		return readTarget{}, err
+	}
+	defer func() {
+		for _, worksheet := range worksheets {
+			worksheet.Release()
+		}
+	}()
+	for _, worksheet := range worksheets {
+		name, err := worksheet.Name()
+		if err != nil {
+			return readTarget{}, err
+		}
+		if sheetName != "" && name != sheetName {
+			continue
+		}
+		tables, err := worksheet.GetTables()
+		if err != nil {
+			return readTarget{}, err
+		}
+		for _, table := range tables {
+			if strings.EqualFold(table.Name, cellRange) {
+				return readTarget{label: fmt.Sprintf("%s (%s)", table.Name, referenceLabel(name, table.Range)), sheetName: name, cellRange: table.Range}, nil
+			}
+		}
+	}
+	return readTarget{}, fmt.Errorf("%s is neither a range of cells, a defined name nor a table name", target.Range)
+}
+
+// splitSheetReference splits a reference such as 'My Sheet'!A1:B2 into the sheet name and the rest.
+// The sheet name is empty if the reference has none.
+func splitSheetReference(reference string) (string, string) {
+	i := strings.LastIndex(reference, "!")
+	if i < 0 {
+		return "", reference
+	}
+	sheetName := reference[:i]
+	if len(sheetName) >= 2 && strings.HasPrefix(sheetName, "'") && strings.HasSuffix(sheetName, "'") {
+		sheetName = strings.ReplaceAll(sheetName[1:len(sheetName)-1], "''", "'")
+	}
+	return sheetName, reference[i+1:]
+}
+
+// referenceLabel returns the reference of the range on the sheet, quoting the sheet name if needed.
+func referenceLabel(sheetName string, cellRange string) string {
+	if strings.ContainsAny(sheetName, " '!-()") {
+		sheetName = "'" + strings.ReplaceAll(sheetName, "'", "''") + "'"
+	}
+	return sheetName + "!" + cellRange
+}
+
+// findDefinedName returns what the defined name refers to. A name scoped to the sheet takes precedence over
+// a name of the workbook scope. Without the sheet name, a name of the sheet scope is found only if no other sheet
+// or the workbook has the same name.
+func findDefinedName(definedNames []excel.DefinedName, name string, sheetName string) (string, bool) {
+	refersTo, found := "", false
+	var sheetScoped []string
+	for _, definedName := range definedNames {
+		// Some backends qualify the names of the sheet scope with the sheet name instead of the scope
+		scope, n := splitSheetReference(definedName.Name)
+		if scope == "" {
+			scope = definedName.Scope
+		}
+		if !strings.EqualFold(n, name) {
+			continue
+		}
+		switch {
+		case sheetName != "" && scope == sheetName:
+			return definedName.RefersTo, true
+		case scope == "" || scope == "Workbook":
+			refersTo, found = definedName.RefersTo, true
+		case sheetName == "":
+			sheetScoped = append(sheetScoped, definedName.RefersTo)
+		}
+	}
+	if !found && len(sheetScoped) == 1 {
+		return sheetScoped[0], true
+	}
+	return refersTo, found
+}
+
+// clipToUsedRange returns the part of the range within the used range of the sheet.
+func clipToUsedRange(workbook excel.Excel, sheetName string, cellRange string) (string, error) {
+	worksheet, err := workbook.FindSheet(sheetName)
+	if err != nil {
+		return "", err
+	}
+	defer worksheet.Release()
+	usedRange, err := worksheet.GetDimension()
+	if err != nil {
+		return "", err
+	}
+	startCol, startRow, endCol, endRow, err := excel.ParseRange(cellRange)
+	if err != nil {
+		return "", err
+	}
+	usedStartCol, usedStartRow, usedEndCol, usedEndRow, err := excel.ParseRange(usedRange)
+	if err != nil {
+		return "", err
+	}
+	startCol, startRow = max(startCol, usedStartCol), max(startRow, usedStartRow)
+	endCol, endRow = min(endCol, usedEndCol), min(endRow, usedEndRow)
+	if startCol > endCol || startRow > endRow {
+		return "", fmt.Errorf("range is outside of used range: %s is not within %s", cellRange, usedRange)
+	}
+	return excel.RangeName(startCol, startRow, endCol, endRow), nil
+}
+
+// splitReadTargets splits the targets into the blocks to read which fit into the limit of the number of cells,
+// and the targets to read next. A target which does not fit is split by rows. The first block has at least one row.
+func splitReadTargets(targets []readTarget, cellsLimit int) ([]readBlock, []ExcelReadTarget, error) {
+	var blocks []readBlock
+	remaining := cellsLimit
+	for i, target := range targets {
+		startCol, startRow, endCol, endRow, err := excel.ParseRange(target.cellRange)
+		if err != nil {
+			return nil, nil, err
+		}
+		columns := endCol - startCol + 1
+		rows := min(endRow-startRow+1, remaining/columns)
+		if len(blocks) == 0 {
+			rows = max(rows, 1)
+		}
+		if rows > 0 {
+			blocks = append(blocks, readBlock{target: target, startCol: startCol, startRow: startRow, endCol: endCol, endRow: startRow + rows - 1})
+			remaining -= rows * columns
+		}
+		if startRow+rows-1 < endRow {
+			nextTargets := []ExcelReadTarget{{SheetName: target.sheetName, Range: excel.RangeName(startCol, startRow+rows, endCol, endRow)}}
+			for _, rest := range targets[i+1:] {
+				nextTargets = append(nextTargets, ExcelReadTarget{SheetName: rest.sheetName, Range: rest.cellRange})
+			}
+			return blocks, nextTargets, nil
+		}
+	}
+	return blocks, nil, nil
+}
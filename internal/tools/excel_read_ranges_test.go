@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSplitSheetReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		sheetName string
+		rest      string
+	}{
+		{"A1:B2", "", "A1:B2"},
+		{"Sheet1!$A$1:$B$2", "Sheet1", "$A$1:$B$2"},
+		{"'My ''Data'''!A1", "My 'Data'", "A1"},
+	}
+	for _, tt := range tests {
+		sheetName, rest := splitSheetReference(tt.reference)
+		if sheetName != tt.sheetName || rest != tt.rest {
+			t.Errorf("splitSheetReference(%q) = %q, %q, want %q, %q", tt.reference, sheetName, rest, tt.sheetName, tt.rest)
+		}
+	}
+}
+
+func TestResolveReadTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	file := excelize.NewFile()
+	file.NewSheet("My Data")
+	file.SetSheetRow("My Data", "A1", &[]any{"k", "v"})
+	file.SetSheetRow("My Data", "A2", &[]any{"x", 1})
+	if err := file.AddTable("My Data", &excelize.Table{Range: "A1:B2", Name: "KV"}); err != nil {
+		t.Fatal(err)
+	}
+	file.SetDefinedName(&excelize.DefinedName{Name: "Total", RefersTo: "Sheet1!$B$2:$B$5"})
+	file.SetDefinedName(&excelize.DefinedName{Name: "Total", RefersTo: "'My Data'!$B$2", Scope: "My Data"})
+	file.SetDefinedName(&excelize.DefinedName{Name: "Rate", RefersTo: "'My Data'!$B$2", Scope: "My Data"})
+	file.SetDefinedName(&excelize.DefinedName{Name: "Key", RefersTo: "Sheet1!$A$1", Scope: "Sheet1"})
+	file.SetDefinedName(&excelize.DefinedName{Name: "Key", RefersTo: "'My Data'!$A$1", Scope: "My Data"})
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	workbook, release, err := excel.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	definedNames, err := workbook.GetDefinedNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target ExcelReadTarget
+		want   readTarget
+	}{
+		{ExcelReadTarget{SheetName: "Sheet1", Range: "A1:C3"}, readTarget{label: "Sheet1!A1:C3", sheetName: "Sheet1", cellRange: "A1:C3"}},
+		{ExcelReadTarget{Range: "'My Data'!$A$1"}, readTarget{label: "'My Data'!A1:A1", sheetName: "My Data", cellRange: "A1:A1"}},
+		{ExcelReadTarget{Range: "Total"}, readTarget{label: "Total (Sheet1!B2:B5)", sheetName: "Sheet1", cellRange: "B2:B5"}},
+		// A name of the sheet scope takes precedence
+		{ExcelReadTarget{SheetName: "My Data", Range: "Total"}, readTarget{label: "Total ('My Data'!B2:B2)", sheetName: "My Data", cellRange: "B2:B2"}},
+		// The only name of the sheet scope is found without the sheet name
+		{ExcelReadTarget{Range: "Rate"}, readTarget{label: "Rate ('My Data'!B2:B2)", sheetName: "My Data", cellRange: "B2:B2"}},
+		{ExcelReadTarget{SheetName: "Sheet1", Range: "Key"}, readTarget{label: "Key (Sheet1!A1:A1)", sheetName: "Sheet1", cellRange: "A1:A1"}},
+		{ExcelReadTarget{Range: "kv"}, readTarget{label: "KV ('My Data'!A1:B2)", sheetName: "My Data", cellRange: "A1:B2"}},
+	}
+	for _, tt := range tests {
+		got, err := resolveReadTarget(workbook, definedNames, tt.target)
+		if err != nil {
+			t.Errorf("resolveReadTarget(%v) error: %v", tt.target, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveReadTarget(%v) = %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+
+	for _, target := range []ExcelReadTarget{{Range: "A1:C3"}, {Range: "Unknown"}, {SheetName: "Sheet2", Range: "Sheet1!A1"},
+		// Names of the sheet scope are not visible from another sheet, and ambiguous without the sheet name
+		{SheetName: "Sheet1", Range: "Rate"}, {Range: "Key"}} {
+		if _, err := resolveReadTarget(workbook, definedNames, target); err == nil {
+			t.Errorf("resolveReadTarget(%v) should fail", target)
+		}
+	}
+}
+
+func TestSplitReadTargets(t *testing.T) {
+	targets := []readTarget{
+		{label: "a", sheetName: "Sheet1", cellRange: "A1:C4"},
+		{label: "b", sheetName: "Sheet2", cellRange: "A1:B2"},
+	}
+	tests := []struct {
+		cellsLimit  int
+		ranges      []string
+		nextTargets []ExcelReadTarget
+	}{
+		{100, []string{"A1:C4", "A1:B2"}, nil},
+		{13, []string{"A1:C4"}, []ExcelReadTarget{{SheetName: "Sheet2", Range: "A1:B2"}}},
+		{7, []string{"A1:C2"}, []ExcelReadTarget{{SheetName: "Sheet1", Range: "A3:C4"}, {SheetName: "Sheet2", Range: "A1:B2"}}},
+		// The first block has at least one row
+		{1, []string{"A1:C1"}, []ExcelReadTarget{{SheetName: "Sheet1", Range: "A2:C4"}, {SheetName: "Sheet2", Range: "A1:B2"}}},
+	}
+	for _, tt := range tests {
+		blocks, nextTargets, err := splitReadTargets(targets, tt.cellsLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ranges []string
+		for _, block := range blocks {
+			ranges = append(ranges, block.cellRange())
+		}
+		if !reflect.DeepEqual(ranges, tt.ranges) || !reflect.DeepEqual(nextTargets, tt.nextTargets) {
+			t.Errorf("splitReadTargets(%d) = %v, %v, want %v, %v", tt.cellsLimit, ranges, nextTargets, tt.ranges, tt.nextTargets)
+		}
+	}
+}
@@ -3,11 +3,10 @@ package tools
 import (
 	"context"
 	"fmt"
-	"html"
+	"slices"
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -18,6 +17,20 @@ type ExcelReadSheetArguments struct {
 	Range            string `zog:"range"`
 	ShowFormula      bool   `zog:"showFormula"`
 	ShowStyle        bool   `zog:"showStyle"`
+	ShowTypes        bool   `zog:"showTypes"`
+	OutputFormat     string `zog:"outputFormat"`
+	JsonLayout       string `zog:"jsonLayout"`
+	Sparse           string `zog:"sparse"`
+}
+
+// readSheetOptions controls what excel_read_sheet reads and how it renders the cells.
+type readSheetOptions struct {
+	ShowFormula  bool
+	ShowStyle    bool
+	ShowTypes    bool
+	OutputFormat string
+	JsonLayout   string
+	Sparse       string
 }
 
 var excelReadSheetArgumentsSchema = z.Struct(z.Shape{
@@ -26,11 +39,28 @@ var excelReadSheetArgumentsSchema = z.Struct(z.Shape{
 	"range":            z.String(),
 	"showFormula":      z.Bool().Default(false),
 	"showStyle":        z.Bool().Default(false),
+	"showTypes":        z.Bool().Default(false),
+	"outputFormat":     z.String().OneOf(outputFormats).Default(OutputFormatHTML),
+	"jsonLayout":       z.String().OneOf(jsonLayouts).Default(JsonLayoutRows),
+	"sparse":           z.String().OneOf(sparseModes).Default(SparseModeOff),
 })
 
-func AddExcelReadSheetTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_read_sheet",
+type ExcelReadSheetOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Range     string `json:"range"`
+	NextRange string `json:"nextRange,omitempty" jsonschema:"Range to read next, absent if this is the last range"`
+	// NextRangeRight and NextRangeDown are set when the page is a block of the columns of the sheet
+	NextRangeRight string `json:"nextRangeRight,omitempty" jsonschema:"Range on the right of this page, covering the same rows"`
+	NextRangeDown  string `json:"nextRangeDown,omitempty" jsonschema:"Range below this page, covering the same columns"`
+}
+
+func AddExcelReadSheetTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_read_sheet",
 		mcp.WithDescription("Read values from Excel sheet with pagination."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelReadSheetOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -46,7 +76,22 @@ func AddExcelReadSheetTool(server *server.MCPServer) {
 			mcp.Description("Show formula instead of value"),
 		),
 		mcp.WithBoolean("showStyle",
-			mcp.Description("Show style information for cells"),
+			mcp.Description("Show style information for cells. Not supported by the markdown and csv output formats"),
+		),
+		mcp.WithBoolean("showTypes",
+			mcp.Description("Show the type (number, date, bool, string, error or formula), the raw value and the number format of each cell, e.g. to tell a date or a formatted number from a text. Not supported by the markdown and csv output formats"),
+		),
+		mcp.WithString("outputFormat",
+			mcp.Description("Format of the cells: html, markdown, csv or json. Plain formats need fewer tokens for numeric data (default: html)"),
+			mcp.Enum(outputFormats...),
+		),
+		mcp.WithString("jsonLayout",
+			mcp.Description("Layout of the json output format: rows (array of row arrays) or records (objects keyed by the first row of the range) (default: rows)"),
+			mcp.Enum(jsonLayouts...),
+		),
+		mcp.WithString("sparse",
+			mcp.Description("How to read a sheet with many empty cells: off (all the cells), collapse (omit fully empty rows and columns, marking where they were) or cells (only non-empty cells as pairs of the address and the value; jsonLayout is ignored). Except for off, the pages are calculated by the number of non-empty cells (default: off)"),
+			mcp.Enum(sparseModes...),
 		),
 	), WithRecovery(handleReadSheet))
 }
@@ -56,14 +101,24 @@ func handleReadSheet(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	if issues := excelReadSheetArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
-	return readSheet(args.FileAbsolutePath, args.SheetName, args.Range, args.ShowFormula, args.ShowStyle)
+	return readSheet(ctx, args.FileAbsolutePath, args.SheetName, args.Range, readSheetOptions{
+		ShowFormula:  args.ShowFormula,
+		ShowStyle:    args.ShowStyle,
+		ShowTypes:    args.ShowTypes,
+		OutputFormat: args.OutputFormat,
+		JsonLayout:   args.JsonLayout,
+		Sparse:       args.Sparse,
+	})
 }
 
-func readSheet(fileAbsolutePath string, sheetName string, valueRange string, showFormula bool, showStyle bool) (*mcp.CallToolResult, error) {
+func readSheet(ctx context.Context, fileAbsolutePath string, sheetName string, valueRange string, options readSheetOptions) (*mcp.CallToolResult, error) {
 	config, issues := LoadConfig()
 	if issues != nil {
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
+	if err := options.validate(); err != nil {
+		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
+	}
 
 	workbook, release, err := excel.OpenFile(fileAbsolutePath)
 	if err != nil {
@@ -78,26 +133,46 @@ func readSheet(fileAbsolutePath string, sheetName string, valueRange string, sho
 	defer worksheet.Release()
 
 	// ページング戦略の初期化
-	strategy, err := worksheet.GetPagingStrategy(config.EXCEL_MCP_PAGING_CELLS_LIMIT)
+	var strategy excel.PagingStrategy
+	if options.Sparse == SparseModeOff {
+		strategy, err = newPagingStrategy(ctx, config, worksheet, options)
+	} else {
+		// Empty cells are collapsed or omitted, so they do not count for the pages
+		strategy, err = excel.NewNonEmptyCellsPagingStrategy(ctx, config.EXCEL_MCP_PAGING_CELLS_LIMIT, worksheet)
+	}
 	if err != nil {
 		return nil, err
 	}
 	pagingService := excel.NewPagingRangeService(strategy)
 
 	// 利用可能な範囲を取得
-	allRanges := pagingService.GetPagingRanges()
-	if len(allRanges) == 0 {
-		return imcp.NewToolResultInvalidArgumentError("no range available to read"), nil
-	}
-
-	// 現在の範囲を決定
+	var allRanges []string
 	currentRange := valueRange
-	if currentRange == "" && len(allRanges) > 0 {
-		currentRange = allRanges[0]
+	var nextRange string
+	if finder, ok := strategy.(excel.PageFinder); ok {
+		// Only the pages around the current range are calculated, since calculating all of them reads the whole sheet
+		if currentRange == "" {
+			currentRange = finder.FirstRange()
+		}
+		if currentRange != "" {
+			nextRange = finder.NextRange(currentRange)
+		}
+	} else {
+		allRanges = pagingService.GetPagingRanges()
+		if len(allRanges) > 0 && currentRange == "" {
+			currentRange = allRanges[0]
+		}
+		// Find next paging range if current range matches a paging range
+		nextRange = pagingService.FindNextRange(allRanges, currentRange)
+	}
+	if err := ctx.Err(); err != nil {
+		// The paging strategy may have stopped reading the cells
+		return nil, err
+	}
+	if currentRange == "" {
+		return imcp.NewToolResultInvalidArgumentError("no range available to read"), nil
 	}
 
-	// Find next paging range if current range matches a paging range
-	nextRange := pagingService.FindNextRange(allRanges, currentRange)
 	// Validate the current range against the used range
 	usedRange, err := worksheet.GetDimension()
 	if err != nil {
@@ -107,48 +182,111 @@ func readSheet(fileAbsolutePath string, sheetName string, valueRange string, sho
 		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
 	}
 
-	// 範囲を解析
-	startCol, startRow, endCol, endRow, err := excel.ParseRange(currentRange)
+	// セルの読み込み
+	table, err := readSheetTable(ctx, worksheet, currentRange, options)
 	if err != nil {
 		return nil, err
 	}
 
-	// HTMLテーブルの生成
-	var table *string
-	if showStyle {
-		if showFormula {
-			table, err = CreateHTMLTableOfFormulaWithStyle(worksheet, startCol, startRow, endCol, endRow)
-		} else {
-			table, err = CreateHTMLTableOfValuesWithStyle(worksheet, startCol, startRow, endCol, endRow)
-		}
-	} else {
-		if showFormula {
-			table, err = CreateHTMLTableOfFormula(worksheet, startCol, startRow, endCol, endRow)
-		} else {
-			table, err = CreateHTMLTableOfValues(worksheet, startCol, startRow, endCol, endRow)
+	output := sheetOutput{
+		title:      "Read Sheet",
+		backend:    workbook.GetBackendName(),
+		sheetName:  sheetName,
+		rangeLabel: "read range",
+		cellRange:  currentRange,
+		nextRange:  nextRange,
+	}
+	// A page after the first one lacks the header row, and a block of columns of a very wide sheet
+	// also lacks the header column. They are repeated from the first row and column of the used range
+	// or from the header row of the table given by the paging strategy.
+	var headerRows []int
+	headerColumn := 0
+	if provider, ok := strategy.(excel.HeaderRowProvider); ok {
+		headerRows = provider.HeaderRows(currentRange)
+	}
+	if isColumnBlock(strategy, allRanges, table, usedRange) {
+		output.nextRangeRight = pagingService.FindNextRangeRight(allRanges, currentRange)
+		output.nextRangeDown = pagingService.FindNextRangeDown(allRanges, currentRange)
+		var headerRow int
+		headerColumn, headerRow, _, _, err = excel.ParseRange(usedRange)
+		if err != nil {
+			return nil, err
 		}
+		headerRows = slices.Repeat([]int{headerRow}, table.endCol-table.startCol+1)
 	}
+	table.headers, err = readHeaderContext(worksheet, table, headerRows, headerColumn)
 	if err != nil {
 		return nil, err
 	}
-
-	result := "<h2>Read Sheet</h2>\n"
-	result += *table + "\n"
-	result += "<h2>Metadata</h2>\n"
-	result += "<ul>\n"
-	result += fmt.Sprintf("<li>backend: %s</li>\n", workbook.GetBackendName())
-	result += fmt.Sprintf("<li>sheet name: %s</li>\n", html.EscapeString(sheetName))
-	result += fmt.Sprintf("<li>read range: %s</li>\n", currentRange)
-	result += "</ul>\n"
-	result += "<h2>Notice</h2>\n"
+	switch options.Sparse {
+	case SparseModeCollapse:
+		table.collapseEmpty()
+	case SparseModeCells:
+		table.cellList = true
+	}
 	if nextRange != "" {
-		result += "<p>This sheet has more ranges.</p>\n"
-		result += "<p>To read the next range, you should specify 'range' argument as follows.</p>\n"
-		result += fmt.Sprintf("<code>{ \"range\": \"%s\" }</code>\n", nextRange)
+		output.notices = []string{
+			"This sheet has more ranges.",
+			"To read the next range, you should specify 'range' argument as follows.",
+		}
 	} else {
-		result += "<p>This is the last range or no more ranges available.</p>\n"
+		output.notices = []string{"This is the last range or no more ranges available."}
+	}
+	result, err := renderSheetOutput(options.OutputFormat, options.JsonLayout, table, output)
+	if err != nil {
+		return nil, err
+	}
+	result.StructuredContent = &ExcelReadSheetOutput{
+		Backend:        workbook.GetBackendName(),
+		SheetName:      sheetName,
+		Range:          currentRange,
+		NextRange:      nextRange,
+		NextRangeRight: output.nextRangeRight,
+		NextRangeDown:  output.nextRangeDown,
+	}
+	return result, nil
+}
+
+// validate returns an error if the options do not work together.
+func (o readSheetOptions) validate() error {
+	if o.ShowStyle && (o.OutputFormat == OutputFormatMarkdown || o.OutputFormat == OutputFormatCSV) {
+		return fmt.Errorf("showStyle is not supported by the %s output format", o.OutputFormat)
+	}
+	if o.ShowTypes && (o.OutputFormat == OutputFormatMarkdown || o.OutputFormat == OutputFormatCSV) {
+		return fmt.Errorf("showTypes is not supported by the %s output format", o.OutputFormat)
+	}
+	return nil
+}
+
+// readSheetTable reads the cells of the range along with the merged areas, and the styles and types if the options ask for them.
+func readSheetTable(ctx context.Context, worksheet excel.Worksheet, cellRange string, options readSheetOptions) (*tableData, error) {
+	startCol, startRow, endCol, endRow, err := excel.ParseRange(cellRange)
+	if err != nil {
+		return nil, err
+	}
+	extractor := worksheet.GetValue
+	if options.ShowFormula {
+		extractor = worksheet.GetFormula
+	}
+	var styleExtractor func(cellRange string) (*excel.CellStyle, error)
+	if options.ShowStyle {
+		styleExtractor = worksheet.GetCellStyle
+	}
+	table, err := readTableData(ctx, startCol, startRow, endCol, endRow, extractor, styleExtractor)
+	if err != nil {
+		return nil, err
+	}
+	merges, err := readMergedAreas(worksheet, cellRange)
+	if err != nil {
+		return nil, err
+	}
+	table.setMergedAreas(merges)
+	if options.ShowTypes {
+		if err := table.readCellTypes(ctx, worksheet.GetCellInfo); err != nil {
+			return nil, err
+		}
 	}
-	return mcp.NewToolResultText(result), nil
+	return table, nil
 }
 
 func validateRangeWithinUsedRange(targetRange, usedRange string) error {
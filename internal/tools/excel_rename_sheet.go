@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -24,9 +23,19 @@ var excelRenameSheetArgumentsSchema = z.Struct(z.Shape{
 	"newSheetName":     z.String().Required(),
 })
 
-func AddExcelRenameSheetTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_rename_sheet",
+type ExcelRenameSheetOutput struct {
+	Backend      string `json:"backend"`
+	OldSheetName string `json:"oldSheetName"`
+	NewSheetName string `json:"newSheetName"`
+	WriteOutput
+}
+
+func AddExcelRenameSheetTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_rename_sheet",
 		mcp.WithDescription("Rename a sheet in the Excel file"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelRenameSheetOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -67,5 +76,9 @@ func renameSheet(fileAbsolutePath string, oldSheetName string, newSheetName stri
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Sheet [%s] renamed to [%s].\n", html.EscapeString(oldSheetName), html.EscapeString(newSheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelRenameSheetOutput{
+		Backend:      workbook.GetBackendName(),
+		OldSheetName: oldSheetName,
+		NewSheetName: newSheetName,
+	}, result), nil
 }
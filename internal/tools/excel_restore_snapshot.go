@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelRestoreSnapshotArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+	SnapshotId       string `zog:"snapshotId"`
+}
+
+var excelRestoreSnapshotArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+	"snapshotId":       z.String().Required(),
+})
+
+type ExcelRestoreSnapshotOutput struct {
+	RestoredSnapshot Snapshot `json:"restoredSnapshot"`
+	WriteOutput
+}
+
+func AddExcelRestoreSnapshotTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_restore_snapshot",
+		mcp.WithDescription("Restore the Excel file to the state before the tool call of the specified snapshot. The current state is kept as a new snapshot, so the restore can be undone with excel_undo"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelRestoreSnapshotOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+		mcp.WithString("snapshotId",
+			mcp.Required(),
+			mcp.Description("ID of the snapshot listed by excel_list_snapshots"),
+		),
+	), WithRecovery(handleRestoreSnapshot))
+}
+
+func handleRestoreSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelRestoreSnapshotArguments{}
+	if issues := excelRestoreSnapshotArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	return restoreSnapshot(args.FileAbsolutePath, args.SnapshotId)
+}
+
+func restoreSnapshot(fileAbsolutePath string, snapshotId string) (*mcp.CallToolResult, error) {
+	config, issues := LoadConfig()
+	if issues != nil {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	store := newSnapshotStore(fileAbsolutePath, config)
+	snapshot, err := store.find(snapshotId)
+	if err != nil {
+		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
+	}
+	if err := store.restore(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s: %w", snapshot.ID, err)
+	}
+
+	result := "# Notice\n"
+	result += fmt.Sprintf("Restored %s to snapshot %s, the state before the %s call %s made at %s.\n", fileAbsolutePath, snapshot.ID, snapshot.Tool, snapshot.Arguments, snapshot.CreatedAt.Format("2006-01-02 15:04:05"))
+	return mcp.NewToolResultStructured(&ExcelRestoreSnapshotOutput{
+		RestoredSnapshot: *snapshot,
+	}, result), nil
+}
@@ -6,7 +6,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -23,9 +22,18 @@ var excelRunMacroArgumentsSchema = z.Struct(z.Shape{
 	"args":             z.Slice(z.String()),
 })
 
-func AddExcelRunMacroTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_run_macro",
+type ExcelRunMacroOutput struct {
+	MacroName   string `json:"macroName"`
+	ReturnValue string `json:"returnValue,omitempty"`
+	WriteOutput
+}
+
+func AddExcelRunMacroTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_run_macro",
 		mcp.WithDescription("Run a VBA macro in an Excel workbook (Windows only)"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelRunMacroOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file containing the macro"),
@@ -80,5 +88,8 @@ func runMacro(fileAbsolutePath string, macroName string, args []string) (*mcp.Ca
 	if macroResult != "" {
 		result += fmt.Sprintf("Return value: %s\n", macroResult)
 	}
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelRunMacroOutput{
+		MacroName:   macroName,
+		ReturnValue: macroResult,
+	}, result), nil
 }
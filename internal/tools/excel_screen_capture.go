@@ -6,7 +6,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	"github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -23,9 +22,19 @@ var ExcelScreenCaptureArgumentsSchema = z.Struct(z.Shape{
 	"range":            z.String(),
 })
 
-func AddExcelScreenCaptureTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_screen_capture",
+type ExcelScreenCaptureOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Range     string `json:"range"`
+	NextRange string `json:"nextRange,omitempty" jsonschema:"Range to capture next, absent if this is the last range"`
+}
+
+func AddExcelScreenCaptureTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessRead, mcp.NewTool("excel_screen_capture",
 		mcp.WithDescription("[Windows only] Take a screenshot of the Excel sheet with pagination."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelScreenCaptureOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -107,9 +116,16 @@ func readSheetImage(fileAbsolutePath string, sheetName string, rangeStr string)
 	}
 
 	// 結果を返却
-	return mcp.NewToolResultImage(
+	result := mcp.NewToolResultImage(
 		text,
 		base64image,
 		"image/png",
-	), nil
+	)
+	result.StructuredContent = &ExcelScreenCaptureOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Range:     currentRange,
+		NextRange: nextRange,
+	}
+	return result, nil
 }
@@ -8,7 +8,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -27,9 +26,20 @@ var excelSetColumnWidthArgumentsSchema = z.Struct(z.Shape{
 	"width":            z.Float64().GTE(0).LTE(255).Required(),
 })
 
-func AddExcelSetColumnWidthTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_set_column_width",
+type ExcelSetColumnWidthOutput struct {
+	Backend   string  `json:"backend"`
+	SheetName string  `json:"sheetName"`
+	Column    string  `json:"column"`
+	Width     float64 `json:"width"`
+	WriteOutput
+}
+
+func AddExcelSetColumnWidthTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_set_column_width",
 		mcp.WithDescription("Set the width of one or more columns"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelSetColumnWidthOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -87,5 +97,10 @@ func setColumnWidth(fileAbsolutePath string, sheetName string, column string, wi
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Column width set to %.1f for [%s] in sheet [%s].\n", width, column, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelSetColumnWidthOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Column:    column,
+		Width:     width,
+	}, result), nil
 }
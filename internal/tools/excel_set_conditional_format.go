@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -36,9 +35,20 @@ var excelSetConditionalFormatArgumentsSchema = z.Struct(z.Shape{
 	"bgColor":          z.String(),
 })
 
-func AddExcelSetConditionalFormatTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_set_conditional_format",
+type ExcelSetConditionalFormatOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Range     string `json:"range"`
+	RuleType  string `json:"ruleType"`
+	WriteOutput
+}
+
+func AddExcelSetConditionalFormatTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_set_conditional_format",
 		mcp.WithDescription("Apply conditional formatting rules to a range of cells"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelSetConditionalFormatOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -104,5 +114,10 @@ func setConditionalFormat(fileAbsolutePath string, sheetName string, formatRange
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Conditional formatting (%s) applied to %s in sheet [%s].\n", ruleType, formatRange, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelSetConditionalFormatOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Range:     formatRange,
+		RuleType:  ruleType,
+	}, result), nil
 }
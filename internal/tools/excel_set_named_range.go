@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -26,9 +25,20 @@ var excelSetNamedRangeArgumentsSchema = z.Struct(z.Shape{
 	"scope":            z.String(),
 })
 
-func AddExcelSetNamedRangeTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_set_named_range",
+type ExcelSetNamedRangeOutput struct {
+	Backend  string `json:"backend"`
+	Name     string `json:"name"`
+	RefersTo string `json:"refersTo"`
+	Scope    string `json:"scope,omitempty"`
+	WriteOutput
+}
+
+func AddExcelSetNamedRangeTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_set_named_range",
 		mcp.WithDescription("Create or update a named range in the workbook"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelSetNamedRangeOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -72,5 +82,10 @@ func setNamedRange(fileAbsolutePath string, name string, refersTo string, scope
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Named range \"%s\" set to %s.\n", html.EscapeString(name), html.EscapeString(refersTo))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelSetNamedRangeOutput{
+		Backend:  workbook.GetBackendName(),
+		Name:     name,
+		RefersTo: refersTo,
+		Scope:    scope,
+	}, result), nil
 }
@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -28,9 +27,21 @@ var excelSetRowHeightArgumentsSchema = z.Struct(z.Shape{
 	"height":           z.Float64().GTE(0).LTE(409).Required(),
 })
 
-func AddExcelSetRowHeightTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_set_row_height",
+type ExcelSetRowHeightOutput struct {
+	Backend   string  `json:"backend"`
+	SheetName string  `json:"sheetName"`
+	Row       int     `json:"row"`
+	EndRow    int     `json:"endRow"`
+	Height    float64 `json:"height"`
+	WriteOutput
+}
+
+func AddExcelSetRowHeightTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_set_row_height",
 		mcp.WithDescription("Set the height of one or more rows"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelSetRowHeightOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -93,5 +104,11 @@ func setRowHeight(fileAbsolutePath string, sheetName string, row int, endRow int
 	} else {
 		result += fmt.Sprintf("Row height set to %.1f for rows %d-%d in sheet [%s].\n", height, row, endRow, html.EscapeString(sheetName))
 	}
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelSetRowHeightOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Row:       row,
+		EndRow:    endRow,
+		Height:    height,
+	}, result), nil
 }
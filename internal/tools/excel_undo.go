@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelUndoArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+}
+
+var excelUndoArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+})
+
+type ExcelUndoOutput struct {
+	RestoredSnapshot   Snapshot `json:"restoredSnapshot"`
+	RemainingSnapshots int      `json:"remainingSnapshots"`
+}
+
+func AddExcelUndoTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_undo",
+		mcp.WithDescription("Revert the latest modifying tool call on the Excel file by restoring the latest snapshot. The snapshot is removed from the history, so calling it again reverts the call before"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelUndoOutput](),
+		mcp.WithString("fileAbsolutePath",
+			mcp.Required(),
+			mcp.Description("Absolute path to the Excel file"),
+		),
+	), WithRecovery(handleUndo))
+}
+
+func handleUndo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := ExcelUndoArguments{}
+	if issues := excelUndoArgumentsSchema.Parse(request.Params.Arguments, &args); len(issues) != 0 {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	return undo(args.FileAbsolutePath)
+}
+
+func undo(fileAbsolutePath string) (*mcp.CallToolResult, error) {
+	config, issues := LoadConfig()
+	if issues != nil {
+		return imcp.NewToolResultZogIssueMap(issues), nil
+	}
+	store := newSnapshotStore(fileAbsolutePath, config)
+	snapshots, err := store.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("no snapshot to undo for %s", fileAbsolutePath)), nil
+	}
+	latest := snapshots[0]
+	if err := store.restore(&latest); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s: %w", latest.ID, err)
+	}
+	store.remove(latest.ID)
+
+	result := "# Notice\n"
+	result += fmt.Sprintf("Reverted %s call %s made at %s by restoring snapshot %s.\n", latest.Tool, latest.Arguments, latest.CreatedAt.Format("2006-01-02 15:04:05"), latest.ID)
+	result += fmt.Sprintf("%d snapshot(s) remain.\n", len(snapshots)-1)
+	return mcp.NewToolResultStructured(&ExcelUndoOutput{
+		RestoredSnapshot:   latest,
+		RemainingSnapshots: len(snapshots) - 1,
+	}, result), nil
+}
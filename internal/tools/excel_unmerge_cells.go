@@ -7,7 +7,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	excel "github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 )
@@ -24,9 +23,19 @@ var excelUnmergeCellsArgumentsSchema = z.Struct(z.Shape{
 	"range":            z.String().Required(),
 })
 
-func AddExcelUnmergeCellsTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_unmerge_cells",
+type ExcelUnmergeCellsOutput struct {
+	Backend   string `json:"backend"`
+	SheetName string `json:"sheetName"`
+	Range     string `json:"range"`
+	WriteOutput
+}
+
+func AddExcelUnmergeCellsTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_unmerge_cells",
 		mcp.WithDescription("Unmerge previously merged cells in the specified range"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOutputSchema[ExcelUnmergeCellsOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -73,5 +82,9 @@ func unmergeCells(fileAbsolutePath string, sheetName string, mergeRange string)
 	result := "# Notice\n"
 	result += fmt.Sprintf("backend: %s\n", workbook.GetBackendName())
 	result += fmt.Sprintf("Cells [%s] unmerged in sheet [%s].\n", mergeRange, html.EscapeString(sheetName))
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultStructured(&ExcelUnmergeCellsOutput{
+		Backend:   workbook.GetBackendName(),
+		SheetName: sheetName,
+		Range:     mergeRange,
+	}, result), nil
 }
@@ -6,7 +6,6 @@ import (
 
 	z "github.com/Oudwins/zog"
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	"github.com/negokaz/excel-mcp-server/internal/excel"
 	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
 	"github.com/xuri/excelize/v2"
@@ -18,6 +17,9 @@ type ExcelWriteToSheetArguments struct {
 	NewSheet         bool       `zog:"newSheet"`
 	Range            string     `zog:"range"`
 	Values           [][]string `zog:"values"`
+	OutputFormat     string     `zog:"outputFormat"`
+	JsonLayout       string     `zog:"jsonLayout"`
+	DryRun           bool       `zog:"dryRun"`
 }
 
 var excelWriteToSheetArgumentsSchema = z.Struct(z.Shape{
@@ -26,11 +28,29 @@ var excelWriteToSheetArgumentsSchema = z.Struct(z.Shape{
 	"newSheet":         z.Bool().Required().Default(false),
 	"range":            z.String().Required(),
 	"values":           z.Slice(z.Slice(z.String())).Required(),
+	"outputFormat":     z.String().OneOf(outputFormats).Default(OutputFormatHTML),
+	"jsonLayout":       z.String().OneOf(jsonLayouts).Default(JsonLayoutRows),
+	"dryRun":           z.Bool().Default(false),
 })
 
-func AddExcelWriteToSheetTool(server *server.MCPServer) {
-	server.AddTool(mcp.NewTool("excel_write_to_sheet",
+type ExcelWriteToSheetOutput struct {
+	Backend        string `json:"backend"`
+	SheetName      string `json:"sheetName"`
+	Range          string `json:"range"`
+	NewSheet       bool   `json:"newSheet"`
+	RowsWritten    int    `json:"rowsWritten"`
+	ColumnsWritten int    `json:"columnsWritten"`
+	// HiddenCells are written into a merged area other than its top-left cell
+	HiddenCells []string `json:"hiddenCells,omitempty" jsonschema:"Cells written into a merged area other than its top-left cell, whose values are hidden"`
+	WriteOutput
+}
+
+func AddExcelWriteToSheetTool(registry ToolRegistry) {
+	registry.AddTool(ToolAccessWrite, mcp.NewTool("excel_write_to_sheet",
 		mcp.WithDescription("Write values to the Excel sheet"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOutputSchema[ExcelWriteToSheetOutput](),
 		mcp.WithString("fileAbsolutePath",
 			mcp.Required(),
 			mcp.Description("Absolute path to the Excel file"),
@@ -70,6 +90,17 @@ func AddExcelWriteToSheetTool(server *server.MCPServer) {
 				},
 			}),
 		),
+		mcp.WithString("outputFormat",
+			mcp.Description("Format of the written cells returned as the result: html, markdown, csv or json (default: html)"),
+			mcp.Enum(outputFormats...),
+		),
+		mcp.WithString("jsonLayout",
+			mcp.Description("Layout of the json output format: rows or records (default: rows)"),
+			mcp.Enum(jsonLayouts...),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Return the changes of the cells without writing the file (default: false)"),
+		),
 	), WithRecovery(handleWriteToSheet))
 }
 
@@ -80,24 +111,20 @@ func handleWriteToSheet(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		return imcp.NewToolResultZogIssueMap(issues), nil
 	}
 
-	// zog が any type のスキーマをサポートしていないため、自力で実装
-	valuesArg, ok := request.GetArguments()["values"].([]any)
-	if !ok {
-		return imcp.NewToolResultInvalidArgumentError("values must be a 2D array"), nil
-	}
-	values := make([][]any, len(valuesArg))
-	for i, v := range valuesArg {
-		value, ok := v.([]any)
-		if !ok {
-			return imcp.NewToolResultInvalidArgumentError("values must be a 2D array"), nil
-		}
-		values[i] = value
+	values, err := parseValuesArgument(request.GetArguments()["values"])
+	if err != nil {
+		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
 	}
 
-	return writeSheet(args.FileAbsolutePath, args.SheetName, args.NewSheet, args.Range, values)
+	if args.DryRun {
+		return runDryRun(ctx, args.FileAbsolutePath, func(path string) (*mcp.CallToolResult, error) {
+			return writeSheet(ctx, path, args.SheetName, args.NewSheet, args.Range, values, args.OutputFormat, args.JsonLayout)
+		})
+	}
+	return writeSheet(ctx, args.FileAbsolutePath, args.SheetName, args.NewSheet, args.Range, values, args.OutputFormat, args.JsonLayout)
 }
 
-func writeSheet(fileAbsolutePath string, sheetName string, newSheet bool, rangeStr string, values [][]any) (*mcp.CallToolResult, error) {
+func writeSheet(ctx context.Context, fileAbsolutePath string, sheetName string, newSheet bool, rangeStr string, values [][]any, outputFormat string, jsonLayout string) (*mcp.CallToolResult, error) {
 	workbook, closeFn, err := excel.OpenFile(fileAbsolutePath)
 	if err != nil {
 		return nil, err
@@ -110,9 +137,8 @@ func writeSheet(fileAbsolutePath string, sheetName string, newSheet bool, rangeS
 	}
 
 	// データの整合性チェック
-	rangeRowSize := endRow - startRow + 1
-	if len(values) != rangeRowSize {
-		return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("number of rows in data (%d) does not match range size (%d)", len(values), rangeRowSize)), nil
+	if err := validateValuesSize(values, startCol, startRow, endCol, endRow); err != nil {
+		return imcp.NewToolResultInvalidArgumentError(err.Error()), nil
 	}
 
 	if newSheet {
@@ -129,16 +155,126 @@ func writeSheet(fileAbsolutePath string, sheetName string, newSheet bool, rangeS
 	defer worksheet.Release()
 
 	// データの書き込み
-	wroteFormula := false
+	wroteFormula, hiddenCells, err := setValues(worksheet, startCol, startRow, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := workbook.Save(); err != nil {
+		return nil, err
+	}
+
+	// 書き込んだセルの読み込み
+	extractor := worksheet.GetValue
+	if wroteFormula {
+		extractor = worksheet.GetFormula
+	}
+	table, err := readTableData(ctx, startCol, startRow, endCol, endRow, extractor, nil)
+	if err != nil {
+		return nil, err
+	}
+	merges, err := readMergedAreas(worksheet, rangeStr)
+	if err != nil {
+		return nil, err
+	}
+	table.setMergedAreas(merges)
+	notices := []string{"Values wrote successfully."}
+	if len(hiddenCells) > 0 {
+		notices = append(notices, hiddenCellsNotice(hiddenCells))
+	}
+	result, err := renderSheetOutput(outputFormat, jsonLayout, table, sheetOutput{
+		title:      "Written Sheet",
+		backend:    workbook.GetBackendName(),
+		sheetName:  sheetName,
+		rangeLabel: "read range",
+		cellRange:  rangeStr,
+		notices:    notices,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.StructuredContent = &ExcelWriteToSheetOutput{
+		Backend:        workbook.GetBackendName(),
+		SheetName:      sheetName,
+		Range:          rangeStr,
+		NewSheet:       newSheet,
+		RowsWritten:    endRow - startRow + 1,
+		ColumnsWritten: endCol - startCol + 1,
+		HiddenCells:    hiddenCells,
+	}
+	return result, nil
+}
+
+func isFormula(value string) bool {
+	return len(value) > 0 && value[0] == '='
+}
+
+// parseValuesArgument converts the values argument into a 2D array.
+// zog が any type のスキーマをサポートしていないため、自力で実装
+func parseValuesArgument(arg any) ([][]any, error) {
+	valuesArg, ok := arg.([]any)
+	if !ok {
+		return nil, fmt.Errorf("values must be a 2D array")
+	}
+	values := make([][]any, len(valuesArg))
+	for i, v := range valuesArg {
+		value, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("values must be a 2D array")
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// validateValuesSize checks that the values have the same size as the range.
+func validateValuesSize(values [][]any, startCol int, startRow int, endCol int, endRow int) error {
+	rangeRowSize := endRow - startRow + 1
+	if len(values) != rangeRowSize {
+		return fmt.Errorf("number of rows in data (%d) does not match range size (%d)", len(values), rangeRowSize)
+	}
+	rangeColumnSize := endCol - startCol + 1
 	for i, row := range values {
-		rangeColumnSize := endCol - startCol + 1
 		if len(row) != rangeColumnSize {
-			return imcp.NewToolResultInvalidArgumentError(fmt.Sprintf("number of columns in row %d (%d) does not match range size (%d)", i, len(row), rangeColumnSize)), nil
+			return fmt.Errorf("number of columns in row %d (%d) does not match range size (%d)", i, len(row), rangeColumnSize)
 		}
+	}
+	return nil
+}
+
+// setValues writes the values from the cell at startCol and startRow. It returns true if a formula was written,
+// and the cells written into merged areas other than their top-left cells.
+func setValues(worksheet excel.Worksheet, startCol int, startRow int, values [][]any) (bool, []string, error) {
+	if len(values) == 0 || len(values[0]) == 0 {
+		return false, nil, nil
+	}
+	topLeft, err := excelize.CoordinatesToCellName(startCol, startRow)
+	if err != nil {
+		return false, nil, err
+	}
+	bottomRight, err := excelize.CoordinatesToCellName(startCol+len(values[0])-1, startRow+len(values)-1)
+	if err != nil {
+		return false, nil, err
+	}
+	areas, err := readMergedAreas(worksheet, topLeft+":"+bottomRight)
+	if err != nil {
+		return false, nil, err
+	}
+	wroteFormula := false
+	var hiddenCells []string
+	for i, row := range values {
 		for j, cellValue := range row {
 			cell, err := excelize.CoordinatesToCellName(startCol+j, startRow+i)
 			if err != nil {
-				return nil, err
+				return false, nil, err
+			}
+			if area := findMergedArea(areas, startCol+j, startRow+i); area != nil && !area.isAnchor(startCol+j, startRow+i) {
+				if cellValue == nil || cellValue == "" {
+					// The cell is empty as a part of the merged area.
+					// Some backends write a value of the cell into the top-left cell, so it is not written.
+					continue
+				}
+				hiddenCells = append(hiddenCells, fmt.Sprintf("%s (merged into %s)", cell, area.anchor()))
 			}
 			if cellStr, ok := cellValue.(string); ok && isFormula(cellStr) {
 				// if cellValue is formula, set it as formula
@@ -149,39 +285,9 @@ func writeSheet(fileAbsolutePath string, sheetName string, newSheet bool, rangeS
 				err = worksheet.SetValue(cell, cellValue)
 			}
 			if err != nil {
-				return nil, err
+				return false, nil, err
 			}
 		}
 	}
-
-	if err := workbook.Save(); err != nil {
-		return nil, err
-	}
-
-	// HTMLテーブルの生成
-	var table *string
-	if wroteFormula {
-		table, err = CreateHTMLTableOfFormula(worksheet, startCol, startRow, endCol, endRow)
-	} else {
-		table, err = CreateHTMLTableOfValues(worksheet, startCol, startRow, endCol, endRow)
-	}
-	if err != nil {
-		return nil, err
-	}
-	html := "<h2>Written Sheet</h2>\n"
-	html += *table + "\n"
-	html += "<h2>Metadata</h2>\n"
-	html += "<ul>\n"
-	html += fmt.Sprintf("<li>backend: %s</li>\n", workbook.GetBackendName())
-	html += fmt.Sprintf("<li>sheet name: %s</li>\n", sheetName)
-	html += fmt.Sprintf("<li>read range: %s</li>\n", rangeStr)
-	html += "</ul>\n"
-	html += "<h2>Notice</h2>\n"
-	html += "<p>Values wrote successfully.</p>\n"
-
-	return mcp.NewToolResultText(html), nil
-}
-
-func isFormula(value string) bool {
-	return len(value) > 0 && value[0] == '='
+	return wroteFormula, hiddenCells, nil
 }
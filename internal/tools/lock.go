@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// lockFileRetryInterval is the interval for retrying to create a lock file held by another process
+	lockFileRetryInterval = 100 * time.Millisecond
+	// lockFileWaitTimeout is the maximum time to wait for a lock file held by another process
+	lockFileWaitTimeout = 30 * time.Second
+	// lockFileStaleAge is the age after which a lock file is considered to be left by a crashed process
+	lockFileStaleAge = 10 * time.Minute
+)
+
+// workbookLocks serializes tool calls on the same workbook within this process.
+// Readers share the lock, writers hold it exclusively.
+type workbookLocks struct {
+	mu    sync.Mutex
+	locks map[string]*workbookLock // key: cleaned absolute path
+}
+
+type workbookLock struct {
+	rw   sync.RWMutex
+	refs int
+}
+
+var defaultWorkbookLocks = &workbookLocks{locks: make(map[string]*workbookLock)}
+
+// WithWorkbookLock wraps a tool handler function so that it runs while holding the lock of
+// the workbook given by the fileAbsolutePath argument. Write tools hold the lock exclusively
+// and also create an advisory lock file, so that other server processes do not write the same workbook.
+func WithWorkbookLock(access ToolAccess, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		path, _ := request.GetArguments()["fileAbsolutePath"].(string)
+		if path == "" || !filepath.IsAbs(path) {
+			// Invalid arguments are reported by the handler
+			return handler(ctx, request)
+		}
+		config, issues := LoadConfig()
+		if issues != nil || validatePathInAllowedRoots(path, config.AllowedRoots()) != nil {
+			// Do not create or remove lock files outside of the allowed roots; the handler reports the error
+			return handler(ctx, request)
+		}
+		unlock, err := lockWorkbook(ctx, path, access == ToolAccessWrite)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer unlock()
+		return handler(ctx, request)
+	}
+}
+
+// lockWorkbook acquires the lock of the workbook and returns the function to release it.
+func lockWorkbook(ctx context.Context, absoluteFilePath string, exclusive bool) (func(), error) {
+	path := filepath.Clean(absoluteFilePath)
+	unlock := defaultWorkbookLocks.lock(path, exclusive)
+	if !exclusive {
+		return unlock, nil
+	}
+	unlockFile, err := acquireLockFile(ctx, path)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		unlock()
+	}, nil
+}
+
+// LockWorkbookForWriting acquires the exclusive lock of the workbook outside of a tool call,
+// e.g. to write the unsaved changes of the workbook cache.
+func LockWorkbookForWriting(absoluteFilePath string) (func(), error) {
+	return lockWorkbook(context.Background(), absoluteFilePath, true)
+}
+
+func (l *workbookLocks) lock(path string, exclusive bool) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[path]
+	if !ok {
+		lock = &workbookLock{}
+		l.locks[path] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	if exclusive {
+		lock.rw.Lock()
+	} else {
+		lock.rw.RLock()
+	}
+	return func() {
+		if exclusive {
+			lock.rw.Unlock()
+		} else {
+			lock.rw.RUnlock()
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, path)
+		}
+	}
+}
+
+// lockFilePath returns the path of the advisory lock file for the workbook (e.g. /path/to/.book.xlsx.lock).
+func lockFilePath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".lock")
+}
+
+// acquireLockFile creates the advisory lock file of the workbook, waiting while another process holds it.
+func acquireLockFile(ctx context.Context, path string) (func(), error) {
+	lockPath := lockFilePath(path)
+	if _, err := os.Stat(filepath.Dir(lockPath)); err != nil {
+		// The directory does not exist; saving will fail anyway, so leave the error to it
+		return func() {}, nil
+	}
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("pid=%d host=%s since=%s\n", os.Getpid(), hostname, time.Now().Format(time.RFC3339))
+
+	deadline := time.Now().Add(lockFileWaitTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, writeErr := file.WriteString(owner)
+			closeErr := file.Close()
+			if err := errors.Join(writeErr, closeErr); err != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", lockPath, err)
+			}
+			return func() {
+				os.Remove(lockPath)
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", lockPath, err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockFileStaleAge {
+			// Left by a process which has crashed
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(lockPath)
+			return nil, fmt.Errorf("workbook is locked by another process (%s). If no other process is running, remove %s",
+				strings.TrimSpace(string(holder)), lockPath)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockFileRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWorkbookLocksReadersShare(t *testing.T) {
+	locks := &workbookLocks{locks: make(map[string]*workbookLock)}
+	unlock1 := locks.lock("/tmp/book.xlsx", false)
+	defer unlock1()
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock2 := locks.lock("/tmp/book.xlsx", false)
+		unlock2()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second reader was blocked")
+	}
+}
+
+func TestWorkbookLocksWriterExcludesOthers(t *testing.T) {
+	locks := &workbookLocks{locks: make(map[string]*workbookLock)}
+	unlock := locks.lock("/tmp/book.xlsx", true)
+
+	acquired := make(chan struct{})
+	go func() {
+		unlockReader := locks.lock("/tmp/book.xlsx", false)
+		unlockReader()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("reader acquired the lock while a writer holds it")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("reader was not unblocked after the writer released the lock")
+	}
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("expected released locks to be removed, got %d", len(locks.locks))
+	}
+}
+
+func TestWorkbookLocksOtherPathsAreIndependent(t *testing.T) {
+	locks := &workbookLocks{locks: make(map[string]*workbookLock)}
+	unlock := locks.lock("/tmp/a.xlsx", true)
+	defer unlock()
+
+	acquired := make(chan struct{})
+	go func() {
+		unlockOther := locks.lock("/tmp/b.xlsx", true)
+		unlockOther()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock of another workbook was blocked")
+	}
+}
+
+func TestAcquireLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+
+	unlock, err := acquireLockFile(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lockFilePath(path)); err != nil {
+		t.Fatalf("expected lock file to exist: %v", err)
+	}
+
+	// Another process holding the lock file makes the caller wait until it gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := acquireLockFile(ctx, path); err == nil {
+		t.Fatal("expected acquiring a held lock file to fail")
+	}
+
+	unlock()
+	if _, err := os.Stat(lockFilePath(path)); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file to be removed: %v", err)
+	}
+}
+
+func TestAcquireLockFileRemovesStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	lockPath := lockFilePath(path)
+	if err := os.WriteFile(lockPath, []byte("pid=1 host=crashed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-lockFileStaleAge - time.Minute)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := acquireLockFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("expected stale lock file to be taken over: %v", err)
+	}
+	unlock()
+}
+
+func TestWithWorkbookLockSkipsPathOutsideAllowedRoots(t *testing.T) {
+	t.Setenv("EXCEL_MCP_ALLOWED_ROOTS", t.TempDir())
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+
+	handler := WithWorkbookLock(ToolAccessWrite, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, err := os.Stat(lockFilePath(path)); !os.IsNotExist(err) {
+			t.Errorf("expected no lock file outside of the allowed roots, got %v", err)
+		}
+		return mcp.NewToolResultError("outside of the allowed roots"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"fileAbsolutePath": path}
+	if _, err := handler(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+// mergedArea is a merged range of cells. Only the value of its top-left cell, the anchor, is shown.
+type mergedArea struct {
+	cellRange string
+	startCol  int
+	startRow  int
+	endCol    int
+	endRow    int
+}
+
+// readMergedAreas reads the merged areas which overlap the range.
+func readMergedAreas(worksheet excel.Worksheet, cellRange string) ([]mergedArea, error) {
+	mergedCells, err := worksheet.GetMergedCells(cellRange)
+	if err != nil {
+		return nil, err
+	}
+	areas := make([]mergedArea, 0, len(mergedCells))
+	for _, mergedCell := range mergedCells {
+		startCol, startRow, endCol, endRow, err := excel.ParseRange(mergedCell)
+		if err != nil {
+			return nil, err
+		}
+		areas = append(areas, mergedArea{cellRange: mergedCell, startCol: startCol, startRow: startRow, endCol: endCol, endRow: endRow})
+	}
+	return areas, nil
+}
+
+func (m mergedArea) contains(col int, row int) bool {
+	return m.startCol <= col && col <= m.endCol && m.startRow <= row && row <= m.endRow
+}
+
+func (m mergedArea) isAnchor(col int, row int) bool {
+	return m.startCol == col && m.startRow == row
+}
+
+// findMergedArea returns the merged area which contains the cell, or nil if the cell is not merged.
+func findMergedArea(areas []mergedArea, col int, row int) *mergedArea {
+	for i := range areas {
+		if areas[i].contains(col, row) {
+			return &areas[i]
+		}
+	}
+	return nil
+}
+
+// anchor returns the address of the top-left cell.
+func (m mergedArea) anchor() string {
+	cell, _ := excelize.CoordinatesToCellName(m.startCol, m.startRow)
+	return cell
+}
+
+// setMergedAreas sets the merged areas which overlap the table.
+// The cells other than the anchors are cleared, since some backends return the value of the anchor for them.
+func (t *tableData) setMergedAreas(areas []mergedArea) {
+	t.merges = areas
+	for i, values := range t.values {
+		for j := range values {
+			if area := t.mergedAreaAt(t.startCol+j, t.startRow+i); area != nil && !area.isAnchor(t.startCol+j, t.startRow+i) {
+				values[j] = ""
+			}
+		}
+	}
+}
+
+// mergedRanges returns the ranges of the merged areas which overlap the table.
+func (t *tableData) mergedRanges() []string {
+	var ranges []string
+	for _, area := range t.merges {
+		ranges = append(ranges, area.cellRange)
+	}
+	return ranges
+}
+
+// mergedAreaAt returns the merged area which contains the cell, or nil if the cell is not merged.
+func (t *tableData) mergedAreaAt(col int, row int) *mergedArea {
+	return findMergedArea(t.merges, col, row)
+}
+
+// mergedCellNote returns the note which takes the place of the value of a merged cell other than the anchor,
+// or an empty string if the cell is not such a cell.
+func (t *tableData) mergedCellNote(col int, row int) string {
+	area := t.mergedAreaAt(col, row)
+	if area == nil || area.isAnchor(col, row) {
+		return ""
+	}
+	return fmt.Sprintf("[merged into %s]", area.anchor())
+}
+
+// htmlSpan returns the rowspan and colspan of a cell within the table. The first cell of a merged area
+// in the table spans over the rest of the area, which are not rendered (0, 0).
+// A cell which is not merged spans 1 row and 1 column.
+func (t *tableData) htmlSpan(col int, row int) (int, int) {
+	area := t.mergedAreaAt(col, row)
+	if area == nil {
+		return 1, 1
+	}
+	firstCol, firstRow := max(area.startCol, t.startCol), max(area.startRow, t.startRow)
+	if col != firstCol || row != firstRow {
+		return 0, 0
+	}
+	// Empty rows and columns are not collapsed within a merged area, so all of them are in the table
+	return min(area.endRow, t.endRow) - firstRow + 1, min(area.endCol, t.endCol) - firstCol + 1
+}
+
+// hiddenCellsNotice returns the warning about the values written into merged areas.
+func hiddenCellsNotice(hiddenCells []string) string {
+	return fmt.Sprintf("Warning: values were written into merged areas other than their top-left cells, which are the only cells shown: %s. Write to the top-left cell or unmerge the cells.", strings.Join(hiddenCells, ", "))
+}
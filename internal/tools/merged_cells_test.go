@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+)
+
+func TestTableDataRenderMergedCells(t *testing.T) {
+	table := &tableData{
+		startCol: 2,
+		startRow: 1,
+		endCol:   4,
+		endRow:   3,
+		// Some backends return the value of the anchor for the other cells of a merged area
+		values: [][]string{
+			{"Report", "Report", "Report"},
+			{"East", "1", "2"},
+			{"East", "3", "4"},
+		},
+		styles: NewStyleRegistry(),
+	}
+	table.setMergedAreas([]mergedArea{
+		{cellRange: "A1:D1", startCol: 1, startRow: 1, endCol: 4, endRow: 1},
+		{cellRange: "B2:B3", startCol: 2, startRow: 2, endCol: 2, endRow: 3},
+	})
+
+	want := "<h2>Sheet Data</h2>\n<table>\n" +
+		"<tr><th></th><th>B</th><th>C</th><th>D</th></tr>\n" +
+		"<tr><th>1</th><td colspan=\"3\">[merged into A1]</td></tr>\n" +
+		"<tr><th>2</th><td rowspan=\"2\">East</td><td>1</td><td>2</td></tr>\n" +
+		"<tr><th>3</th><td>3</td><td>4</td></tr>\n" +
+		"</table>"
+	if got := table.renderHTML(); got != want {
+		t.Errorf("renderHTML() =\n%s\nwant\n%s", got, want)
+	}
+
+	want = "|   | B | C | D |\n" +
+		"|---|---|---|---|\n" +
+		"| 1 | [merged into A1] | [merged into A1] | [merged into A1] |\n" +
+		"| 2 | East | 1 | 2 |\n" +
+		"| 3 | [merged into B2] | 3 | 4 |\n"
+	if got := table.renderMarkdown(); got != want {
+		t.Errorf("renderMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSetValuesIntoMergedArea(t *testing.T) {
+	path := newTestBatchWorkbook(t)
+	workbook, release, err := excel.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	worksheet, err := workbook.FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worksheet.Release()
+	if err := worksheet.MergeCells("A1:C1"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, hiddenCells, err := setValues(worksheet, 1, 1, [][]any{{"title", nil, ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hiddenCells != nil {
+		t.Errorf("hiddenCells = %v, want none", hiddenCells)
+	}
+	// Empty values of the other cells do not clear the top-left cell
+	if value, _ := worksheet.GetValue("A1"); value != "title" {
+		t.Errorf("A1 = %q, want %q", value, "title")
+	}
+
+	_, hiddenCells, err = setValues(worksheet, 2, 1, [][]any{{"x", 1.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"B1 (merged into A1)", "C1 (merged into A1)"}; !reflect.DeepEqual(hiddenCells, want) {
+		t.Errorf("hiddenCells = %v, want %v", hiddenCells, want)
+	}
+}
@@ -0,0 +1,634 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+// Output formats of the cells returned by excel_read_sheet and excel_write_to_sheet
+const (
+	OutputFormatHTML     = "html"
+	OutputFormatMarkdown = "markdown"
+	OutputFormatCSV      = "csv"
+	OutputFormatJSON     = "json"
+)
+
+// Layouts of the json output format
+const (
+	// JsonLayoutRows returns an array of row arrays
+	JsonLayoutRows = "rows"
+	// JsonLayoutRecords returns objects keyed by the values of the first row
+	JsonLayoutRecords = "records"
+)
+
+var outputFormats = []string{OutputFormatHTML, OutputFormatMarkdown, OutputFormatCSV, OutputFormatJSON}
+
+var jsonLayouts = []string{JsonLayoutRows, JsonLayoutRecords}
+
+// htmlTextEscaper escapes a text node of HTML. Unlike html.EscapeString, it keeps quotes readable.
+var htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// tableData is the cells of a range read from a worksheet, independent of the output format.
+type tableData struct {
+	startCol int
+	startRow int
+	endCol   int
+	endRow   int
+	values   [][]string
+	// styleRefs holds the style IDs of each cell, or nil if styles were not read
+	styleRefs [][][]string
+	styles    *StyleRegistry
+	// types holds the type of each cell, nil for an empty cell, or nil if types were not read
+	types [][]*cellTypeInfo
+	// headers holds the labels of the rows and columns outside of the range, or nil if there are none
+	headers *headerContext
+	// rowNumbers and colNumbers are the rows and columns of values after the empty ones were collapsed,
+	// or nil if values holds all the rows and columns of the range
+	rowNumbers []int
+	colNumbers []int
+	// cellList renders only the non-empty cells as pairs of the address and the value
+	cellList bool
+	// merges holds the merged areas which overlap the range
+	merges []mergedArea
+}
+
+// sheetOutput is the metadata rendered along with a table.
+type sheetOutput struct {
+	title      string
+	backend    string
+	sheetName  string
+	rangeLabel string
+	cellRange  string
+	nextRange  string
+	// nextRangeRight and nextRangeDown are the adjacent pages when the sheet is paged in both axes
+	nextRangeRight string
+	nextRangeDown  string
+	notices        []string
+}
+
+// hasNotice returns true if there is a notice to render. A block of a read of several ranges has none of its own.
+func (o sheetOutput) hasNotice() bool {
+	return len(o.notices) > 0 || o.nextRange != ""
+}
+
+// headerContext is the labels of a page read from a header row and a header column which the page does not include.
+type headerContext struct {
+	// headerRow is the header row of all the columns, or 0 if side-by-side tables have different header rows
+	headerRow int
+	// columnHeaderRows are the header rows of each column of the page if they differ, 0 for a column without one
+	columnHeaderRows []int
+	headerColumn     string
+	// columnLabels are the values of headerRow in each column of the page, or nil if there is no header row to repeat.
+	// They are rendered as the first row of the table.
+	columnLabels []string
+	// rowLabels are the values of headerColumn in each row of the page, or nil if there is no header column to repeat.
+	// They are rendered as metadata.
+	rowLabels []string
+}
+
+// hasHeaderRow returns true if the header row is repeated at the top of the table.
+func (t *tableData) hasHeaderRow() bool {
+	return t.headers != nil && t.headers.columnLabels != nil
+}
+
+// headerRowOf returns the header row of the j-th column of the page.
+func (h *headerContext) headerRowOf(j int) int {
+	if h.columnHeaderRows != nil {
+		return h.columnHeaderRows[j]
+	}
+	return h.headerRow
+}
+
+// headerRowLabel returns the row number rendered in front of the repeated header row,
+// e.g. "1", or "1,3" if side-by-side tables have different header rows.
+func (h *headerContext) headerRowLabel() string {
+	if h.columnHeaderRows == nil {
+		return strconv.Itoa(h.headerRow)
+	}
+	var rows []string
+	for _, row := range h.columnHeaderRows {
+		if label := strconv.Itoa(row); row > 0 && !slices.Contains(rows, label) {
+			rows = append(rows, label)
+		}
+	}
+	return strings.Join(rows, ",")
+}
+
+// metadata renders the header labels as metadata lines.
+func (h *headerContext) metadata(table *tableData) []string {
+	if h == nil {
+		return nil
+	}
+	var lines []string
+	if h.columnLabels != nil && h.columnHeaderRows == nil {
+		lines = append(lines, fmt.Sprintf("header row: %d (repeated at the top of the table)", h.headerRow))
+	} else if h.columnLabels != nil {
+		// Group the consecutive columns of the same table, e.g. "A:B=1, D:E=3"
+		var groups []string
+		names := table.columnNames()
+		for j := 0; j < len(names); {
+			k := j
+			for k+1 < len(names) && h.columnHeaderRows[k+1] == h.columnHeaderRows[j] {
+				k++
+			}
+			if row := h.columnHeaderRows[j]; row > 0 {
+				columns := names[j]
+				if k > j {
+					columns += ":" + names[k]
+				}
+				groups = append(groups, fmt.Sprintf("%s=%d", columns, row))
+			}
+			j = k + 1
+		}
+		lines = append(lines, fmt.Sprintf("header rows: %s (repeated at the top of the table)", strings.Join(groups, ", ")))
+	}
+	if h.rowLabels != nil {
+		labels := make([]string, len(h.rowLabels))
+		for i, label := range h.rowLabels {
+			labels[i] = fmt.Sprintf("%d=%s", table.rowNumber(i), strconv.Quote(label))
+		}
+		lines = append(lines, fmt.Sprintf("row headers (column %s): %s", h.headerColumn, strings.Join(labels, ", ")))
+	}
+	return lines
+}
+
+// metadata returns the metadata lines other than the backend, the sheet name and the range.
+func (o sheetOutput) metadata(table *tableData) []string {
+	var lines []string
+	if o.nextRangeRight != "" {
+		lines = append(lines, fmt.Sprintf("range on the right: %s", o.nextRangeRight))
+	}
+	if o.nextRangeDown != "" {
+		lines = append(lines, fmt.Sprintf("range below: %s", o.nextRangeDown))
+	}
+	if len(table.merges) > 0 {
+		lines = append(lines, fmt.Sprintf("merged cells: %s", strings.Join(table.mergedRanges(), ", ")))
+	}
+	if runs := table.emptyColumns(); len(runs) > 0 && !table.cellList {
+		labels := make([]string, len(runs))
+		for i, run := range runs {
+			labels[i] = run.columnLabel()
+		}
+		lines = append(lines, fmt.Sprintf("empty columns (omitted): %s", strings.Join(labels, ", ")))
+	}
+	return append(lines, table.headers.metadata(table)...)
+}
+
+// readTableData reads the cells of the range. Styles are read only if styleExtractor is not nil.
+func readTableData(ctx context.Context, startCol int, startRow int, endCol int, endRow int, extractor func(cellRange string) (string, error), styleExtractor func(cellRange string) (*excel.CellStyle, error)) (*tableData, error) {
+	table := &tableData{
+		startCol: startCol,
+		startRow: startRow,
+		endCol:   endCol,
+		endRow:   endRow,
+		values:   make([][]string, 0, endRow-startRow+1),
+		styles:   NewStyleRegistry(),
+	}
+	if styleExtractor != nil {
+		table.styleRefs = make([][][]string, 0, endRow-startRow+1)
+	}
+	for row := startRow; row <= endRow; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reportProgress(ctx, row-startRow, endRow-startRow+1, "reading cells")
+		values := make([]string, 0, endCol-startCol+1)
+		var styleRefs [][]string
+		for col := startCol; col <= endCol; col++ {
+			axis, _ := excelize.CoordinatesToCellName(col, row)
+			value, _ := extractor(axis)
+			values = append(values, value)
+
+			if styleExtractor != nil {
+				styleIDs := []string{}
+				cellStyle, err := styleExtractor(axis)
+				if err == nil && cellStyle != nil {
+					styleIDs = table.styles.RegisterStyle(cellStyle)
+				}
+				styleRefs = append(styleRefs, styleIDs)
+			}
+		}
+		table.values = append(table.values, values)
+		if styleExtractor != nil {
+			table.styleRefs = append(table.styleRefs, styleRefs)
+		}
+	}
+	reportProgress(ctx, endRow-startRow+1, endRow-startRow+1, "reading cells")
+	return table, nil
+}
+
+// rowNumber returns the row number of the i-th row of values.
+func (t *tableData) rowNumber(i int) int {
+	if t.rowNumbers != nil {
+		return t.rowNumbers[i]
+	}
+	return t.startRow + i
+}
+
+// columnNumbers returns the column numbers of the columns of values.
+func (t *tableData) columnNumbers() []int {
+	if t.colNumbers != nil {
+		return t.colNumbers
+	}
+	numbers := make([]int, 0, t.endCol-t.startCol+1)
+	for col := t.startCol; col <= t.endCol; col++ {
+		numbers = append(numbers, col)
+	}
+	return numbers
+}
+
+func (t *tableData) columnNames() []string {
+	numbers := t.columnNumbers()
+	names := make([]string, 0, len(numbers))
+	for _, col := range numbers {
+		name, _ := excelize.ColumnNumberToName(col)
+		names = append(names, name)
+	}
+	return names
+}
+
+// renderHTML renders the table with the style definitions in HTML.
+func (t *tableData) renderHTML() string {
+	if t.cellList {
+		return t.renderCellListHTML()
+	}
+	var result strings.Builder
+	result.WriteString("<table>\n<tr><th></th>")
+
+	// 列アドレスの出力
+	for _, name := range t.columnNames() {
+		result.WriteString(fmt.Sprintf("<th>%s</th>", name))
+	}
+	result.WriteString("</tr>\n")
+
+	if t.hasHeaderRow() {
+		result.WriteString(fmt.Sprintf("<tr><th>%s</th>", t.headers.headerRowLabel()))
+		for _, label := range t.headers.columnLabels {
+			result.WriteString(fmt.Sprintf("<th>%s</th>", strings.ReplaceAll(html.EscapeString(label), "\n", "<br>")))
+		}
+		result.WriteString("</tr>\n")
+	}
+	columns := t.columnNumbers()
+	for _, line := range t.lines() {
+		if line.index < 0 {
+			result.WriteString(fmt.Sprintf("<tr><th></th><td colspan=\"%d\">%s</td></tr>\n", max(len(columns), 1), line.emptyRun.marker()))
+			continue
+		}
+		i, values := line.index, t.values[line.index]
+		row := t.rowNumber(i)
+		result.WriteString("<tr>")
+		result.WriteString(fmt.Sprintf("<th>%d</th>", row))
+		for j, value := range values {
+			// A merged area is rendered as a cell spanning over it
+			rowspan, colspan := t.htmlSpan(columns[j], row)
+			if rowspan == 0 {
+				continue
+			}
+			attributes := ""
+			if rowspan > 1 {
+				attributes += fmt.Sprintf(" rowspan=\"%d\"", rowspan)
+			}
+			if colspan > 1 {
+				attributes += fmt.Sprintf(" colspan=\"%d\"", colspan)
+			}
+			if t.styleRefs != nil && len(t.styleRefs[i][j]) > 0 {
+				attributes += fmt.Sprintf(" style-ref=\"%s\"", strings.Join(t.styleRefs[i][j], " "))
+			}
+			if t.types != nil {
+				attributes += t.types[i][j].htmlAttributes()
+			}
+			tdTag := fmt.Sprintf("<td%s>", attributes)
+			if note := t.mergedCellNote(columns[j], row); note != "" {
+				// The anchor of the merged area is outside of the range
+				value = note
+			}
+			result.WriteString(fmt.Sprintf("%s%s</td>", tdTag, strings.ReplaceAll(html.EscapeString(value), "\n", "<br>")))
+		}
+		result.WriteString("</tr>\n")
+	}
+	result.WriteString("</table>")
+
+	// スタイル定義とテーブルを結合
+	var finalResult strings.Builder
+	finalResult.WriteString(t.styles.GenerateStyleDefinitions())
+	finalResult.WriteString("<h2>Sheet Data</h2>\n")
+	finalResult.WriteString(result.String())
+	return finalResult.String()
+}
+
+// renderMarkdown renders the table in Markdown with the column names and row numbers as headers.
+func (t *tableData) renderMarkdown() string {
+	if t.cellList {
+		return t.renderCellListMarkdown()
+	}
+	var result strings.Builder
+	result.WriteString("|   |")
+	for _, name := range t.columnNames() {
+		result.WriteString(fmt.Sprintf(" %s |", name))
+	}
+	result.WriteString("\n|---|")
+	for range t.columnNames() {
+		result.WriteString("---|")
+	}
+	result.WriteString("\n")
+	if t.hasHeaderRow() {
+		result.WriteString(fmt.Sprintf("| %s |", t.headers.headerRowLabel()))
+		for _, label := range t.headers.columnLabels {
+			result.WriteString(fmt.Sprintf(" %s |", escapeMarkdownTableCell(label)))
+		}
+		result.WriteString("\n")
+	}
+	columns := t.columnNumbers()
+	for _, line := range t.lines() {
+		if line.index < 0 {
+			result.WriteString(fmt.Sprintf("|   | %s |", line.emptyRun.marker()))
+			result.WriteString(strings.Repeat("  |", max(len(columns)-1, 0)))
+			result.WriteString("\n")
+			continue
+		}
+		row := t.rowNumber(line.index)
+		result.WriteString(fmt.Sprintf("| %d |", row))
+		for j, value := range t.values[line.index] {
+			if note := t.mergedCellNote(columns[j], row); note != "" {
+				value = note
+			}
+			result.WriteString(fmt.Sprintf(" %s |", escapeMarkdownTableCell(value)))
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+func escapeMarkdownTableCell(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// renderCSV renders the values of the table in CSV without the column names and row numbers.
+// The repeated header row comes first if any.
+func (t *tableData) renderCSV() (string, error) {
+	if t.cellList {
+		return t.renderCellListCSV()
+	}
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if t.hasHeaderRow() {
+		if err := writer.Write(t.headers.columnLabels); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.WriteAll(t.values); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// jsonRecord is an object whose keys keep the order of the columns.
+type jsonRecord struct {
+	keys   []string
+	values []string
+}
+
+func (r jsonRecord) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, key := range r.keys {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(k)
+		buffer.WriteString(":")
+		buffer.Write(v)
+	}
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+// records converts the rows after the first one into records keyed by the values of the first row.
+// If the header row is repeated, all the rows are converted into records keyed by the header row.
+// A column with an empty or duplicated header is keyed by its column name.
+func (t *tableData) records() []jsonRecord {
+	records := []jsonRecord{}
+	var headers []string
+	rows := t.values
+	if t.hasHeaderRow() {
+		headers = t.headers.columnLabels
+	} else {
+		if len(t.values) == 0 {
+			return records
+		}
+		headers, rows = t.values[0], t.values[1:]
+	}
+	columnNames := t.columnNames()
+	keys := make([]string, len(headers))
+	seen := map[string]bool{}
+	for i, header := range headers {
+		key := strings.TrimSpace(header)
+		if key == "" || seen[key] {
+			key = columnNames[i]
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+	for _, values := range rows {
+		records = append(records, jsonRecord{keys: keys, values: values})
+	}
+	return records
+}
+
+type jsonTableOutput struct {
+	Backend          string            `json:"backend"`
+	SheetName        string            `json:"sheetName"`
+	Range            string            `json:"range"`
+	NextRange        string            `json:"nextRange,omitempty"`
+	NextRangeRight   string            `json:"nextRangeRight,omitempty"`
+	NextRangeDown    string            `json:"nextRangeDown,omitempty"`
+	HeaderRow        int               `json:"headerRow,omitempty"`
+	ColumnHeaderRows []int             `json:"columnHeaderRows,omitempty"`
+	ColumnHeaders    []string          `json:"columnHeaders,omitempty"`
+	HeaderColumn     string            `json:"headerColumn,omitempty"`
+	RowHeaders       []string          `json:"rowHeaders,omitempty"`
+	EmptyRows        []string          `json:"emptyRows,omitempty"`
+	EmptyColumns     []string          `json:"emptyColumns,omitempty"`
+	Columns          []string          `json:"columns,omitempty"`
+	RowNumbers       []int             `json:"rowNumbers,omitempty"`
+	Rows             [][]string        `json:"rows,omitempty"`
+	Records          []jsonRecord      `json:"records,omitempty"`
+	Cells            []sparseCell      `json:"cells,omitempty"`
+	Merges           []string          `json:"merges,omitempty"`
+	Styles           [][]string        `json:"styles,omitempty"`
+	StyleDefinitions map[string]string `json:"styleDefinitions,omitempty"`
+	Types            [][]*cellTypeInfo `json:"types,omitempty"`
+	Notices          []string          `json:"notices,omitempty"`
+}
+
+// renderSheetOutput renders the table and its metadata in the output format.
+func renderSheetOutput(format string, jsonLayout string, table *tableData, output sheetOutput) (*mcp.CallToolResult, error) {
+	switch format {
+	case OutputFormatMarkdown:
+		result := fmt.Sprintf("## %s\n", output.title)
+		result += table.renderMarkdown() + "\n"
+		result += "## Metadata\n"
+		result += fmt.Sprintf("- backend: %s\n", output.backend)
+		result += fmt.Sprintf("- sheet name: %s\n", output.sheetName)
+		result += fmt.Sprintf("- %s: %s\n", output.rangeLabel, output.cellRange)
+		for _, line := range output.metadata(table) {
+			result += fmt.Sprintf("- %s\n", line)
+		}
+		if output.hasNotice() {
+			result += "\n## Notice\n"
+			for _, notice := range output.notices {
+				result += notice + "\n"
+			}
+			if output.nextRange != "" {
+				result += fmt.Sprintf("`{ \"range\": \"%s\" }`\n", output.nextRange)
+			}
+		}
+		return mcp.NewToolResultText(result), nil
+	case OutputFormatCSV:
+		data, err := table.renderCSV()
+		if err != nil {
+			return nil, err
+		}
+		// CSV has no place for metadata, so it is returned as a separate content
+		notice := "# Notice\n"
+		notice += fmt.Sprintf("backend: %s\n", output.backend)
+		notice += fmt.Sprintf("sheet name: %s\n", output.sheetName)
+		notice += fmt.Sprintf("%s: %s\n", output.rangeLabel, output.cellRange)
+		for _, line := range output.metadata(table) {
+			notice += line + "\n"
+		}
+		if runs := table.emptyRows(); len(runs) > 0 && !table.cellList {
+			// CSV has no row numbers, so the omitted rows are listed here instead of the markers
+			labels := make([]string, len(runs))
+			for i, run := range runs {
+				labels[i] = run.rowLabel()
+			}
+			notice += fmt.Sprintf("empty rows (omitted): %s\n", strings.Join(labels, ", "))
+		}
+		if output.nextRange != "" {
+			notice += fmt.Sprintf("next range: %s\n", output.nextRange)
+		}
+		for _, n := range output.notices {
+			notice += n + "\n"
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{mcp.NewTextContent(data), mcp.NewTextContent(notice)},
+		}, nil
+	case OutputFormatJSON:
+		// Compact JSON keeps the output small
+		data, err := json.Marshal(newJsonTableOutput(jsonLayout, table, output))
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	default:
+		result := fmt.Sprintf("<h2>%s</h2>\n", output.title)
+		result += table.renderHTML() + "\n"
+		result += "<h2>Metadata</h2>\n"
+		result += "<ul>\n"
+		result += fmt.Sprintf("<li>backend: %s</li>\n", output.backend)
+		result += fmt.Sprintf("<li>sheet name: %s</li>\n", html.EscapeString(output.sheetName))
+		result += fmt.Sprintf("<li>%s: %s</li>\n", output.rangeLabel, output.cellRange)
+		for _, line := range output.metadata(table) {
+			result += fmt.Sprintf("<li>%s</li>\n", htmlTextEscaper.Replace(line))
+		}
+		result += "</ul>\n"
+		if output.hasNotice() {
+			result += "<h2>Notice</h2>\n"
+			for _, notice := range output.notices {
+				result += fmt.Sprintf("<p>%s</p>\n", notice)
+			}
+			if output.nextRange != "" {
+				result += fmt.Sprintf("<code>{ \"range\": \"%s\" }</code>\n", output.nextRange)
+			}
+		}
+		return mcp.NewToolResultText(result), nil
+	}
+}
+
+// newJsonTableOutput returns the table and its metadata in the json output format.
+func newJsonTableOutput(jsonLayout string, table *tableData, output sheetOutput) jsonTableOutput {
+	result := jsonTableOutput{
+		Backend:        output.backend,
+		SheetName:      output.sheetName,
+		Range:          output.cellRange,
+		NextRange:      output.nextRange,
+		NextRangeRight: output.nextRangeRight,
+		NextRangeDown:  output.nextRangeDown,
+		Notices:        output.notices,
+	}
+	if table.hasHeaderRow() && jsonLayout != JsonLayoutRecords && !table.cellList {
+		result.HeaderRow = table.headers.headerRow
+		result.ColumnHeaderRows = table.headers.columnHeaderRows
+		result.ColumnHeaders = table.headers.columnLabels
+	}
+	if table.headers != nil && table.headers.rowLabels != nil {
+		result.HeaderColumn = table.headers.headerColumn
+		result.RowHeaders = table.headers.rowLabels
+	}
+	result.Merges = table.mergedRanges()
+	if table.cellList {
+		// Styles are given in each cell
+		result.Cells = table.cells()
+		if table.styleRefs != nil {
+			result.StyleDefinitions = table.styles.Definitions()
+		}
+	} else {
+		if jsonLayout == JsonLayoutRecords {
+			result.Records = table.records()
+		} else {
+			result.Columns = table.columnNames()
+			result.Rows = table.values
+		}
+		if table.collapsed() {
+			result.RowNumbers = table.rowNumbers
+			for _, run := range table.emptyRows() {
+				result.EmptyRows = append(result.EmptyRows, run.rowLabel())
+			}
+			for _, run := range table.emptyColumns() {
+				result.EmptyColumns = append(result.EmptyColumns, run.columnLabel())
+			}
+		}
+	}
+	if table.types != nil && !table.cellList {
+		// Types are given for each row of rows or records
+		result.Types = table.types
+		if jsonLayout == JsonLayoutRecords && !table.hasHeaderRow() && len(table.types) > 0 {
+			result.Types = table.types[1:]
+		}
+	}
+	if table.styleRefs != nil && !table.cellList {
+		// Style IDs of each cell are joined with spaces as in the style-ref attribute of HTML
+		result.Styles = make([][]string, len(table.styleRefs))
+		for i, row := range table.styleRefs {
+			result.Styles[i] = make([]string, len(row))
+			for j, styleIDs := range row {
+				result.Styles[i][j] = strings.Join(styleIDs, " ")
+			}
+		}
+		result.StyleDefinitions = table.styles.Definitions()
+	}
+	return result
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newTestTableData() *tableData {
+	return &tableData{
+		startCol: 2,
+		startRow: 5,
+		endCol:   4,
+		endRow:   7,
+		values: [][]string{
+			{"name", "", "name"},
+			{"a|b", "1", "x"},
+			{"line1\nline2", "2", "y"},
+		},
+		styles: NewStyleRegistry(),
+	}
+}
+
+func TestTableDataRenderMarkdown(t *testing.T) {
+	want := "|   | B | C | D |\n" +
+		"|---|---|---|---|\n" +
+		"| 5 | name |  | name |\n" +
+		"| 6 | a\\|b | 1 | x |\n" +
+		"| 7 | line1<br>line2 | 2 | y |\n"
+	if got := newTestTableData().renderMarkdown(); got != want {
+		t.Errorf("renderMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableDataRenderCSV(t *testing.T) {
+	got, err := newTestTableData().renderCSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name,,name\na|b,1,x\n\"line1\nline2\",2,y\n"
+	if got != want {
+		t.Errorf("renderCSV() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSheetOutputJsonRecords(t *testing.T) {
+	result, err := renderSheetOutput(OutputFormatJSON, JsonLayoutRecords, newTestTableData(), sheetOutput{
+		backend:   "excelize",
+		sheetName: "Sheet1",
+		cellRange: "B5:D7",
+		nextRange: "B8:D10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	// Empty and duplicated headers are keyed by the column name
+	want := `{"backend":"excelize","sheetName":"Sheet1","range":"B5:D7","nextRange":"B8:D10",` +
+		`"records":[{"name":"a|b","C":"1","D":"x"},{"name":"line1\nline2","C":"2","D":"y"}]}`
+	if text != want {
+		t.Errorf("got %s\nwant %s", text, want)
+	}
+	if !json.Valid([]byte(text)) {
+		t.Error("invalid JSON")
+	}
+}
+
+func TestRenderSheetOutputMarkdownHeaderContext(t *testing.T) {
+	table := newTestTableData()
+	table.headers = &headerContext{
+		headerRow:    1,
+		headerColumn: "A",
+		columnLabels: []string{"name", "price", "qty"},
+		rowLabels:    []string{"apple", "banana, ripe", ""},
+	}
+	result, err := renderSheetOutput(OutputFormatMarkdown, JsonLayoutRows, table, sheetOutput{
+		title:          "Read Sheet",
+		backend:        "excelize",
+		sheetName:      "Sheet1",
+		rangeLabel:     "read range",
+		cellRange:      "B5:D7",
+		nextRangeRight: "E5:G7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"- range on the right: E5:G7\n",
+		"- header row: 1 (repeated at the top of the table)\n",
+		"- row headers (column A): 5=\"apple\", 6=\"banana, ripe\", 7=\"\"\n",
+		"|---|---|---|---|\n| 1 | name | price | qty |\n| 5 | name |  | name |\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "range below") {
+		t.Errorf("unexpected range below in\n%s", text)
+	}
+}
+
+func TestRenderSheetOutputSideBySideHeaderRows(t *testing.T) {
+	table := newTestTableData()
+	// B is in a table with the header in row 1, and D in a table with the header in row 3
+	table.headers = &headerContext{columnHeaderRows: []int{1, 0, 3}, columnLabels: []string{"name", "", "qty"}}
+	result, err := renderSheetOutput(OutputFormatMarkdown, JsonLayoutRows, table, sheetOutput{
+		title:      "Read Sheet",
+		backend:    "excelize",
+		sheetName:  "Sheet1",
+		rangeLabel: "read range",
+		cellRange:  "B5:D7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"- header rows: B=1, D=3 (repeated at the top of the table)\n",
+		"|---|---|---|---|\n| 1,3 | name |  | qty |\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in\n%s", want, text)
+		}
+	}
+
+	table.cellList = true
+	result, err = renderSheetOutput(OutputFormatJSON, JsonLayoutRows, table, sheetOutput{backend: "excelize", sheetName: "Sheet1", cellRange: "B5:D7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text = result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, `{"cell":"B1","value":"name"},{"cell":"D3","value":"qty"}`) {
+		t.Errorf("expected the labels in their own header rows, got %s", text)
+	}
+}
+
+func TestRenderSheetOutputJsonRecordsRepeatedHeaderRow(t *testing.T) {
+	table := newTestTableData()
+	table.headers = &headerContext{headerRow: 1, columnLabels: []string{"item", "price", "qty"}}
+	result, err := renderSheetOutput(OutputFormatJSON, JsonLayoutRecords, table, sheetOutput{
+		backend:   "excelize",
+		sheetName: "Sheet1",
+		cellRange: "B5:D7",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	// All the rows of the page are records keyed by the repeated header row
+	want := `{"backend":"excelize","sheetName":"Sheet1","range":"B5:D7",` +
+		`"records":[{"item":"name","price":"","qty":"name"},{"item":"a|b","price":"1","qty":"x"},{"item":"line1\nline2","price":"2","qty":"y"}]}`
+	if text != want {
+		t.Errorf("got %s\nwant %s", text, want)
+	}
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"slices"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+// Paging strategies selectable with EXCEL_MCP_PAGING_STRATEGY
+const (
+	// PagingStrategyCells pages by the number of cells (EXCEL_MCP_PAGING_CELLS_LIMIT)
+	PagingStrategyCells = "cells"
+	// PagingStrategyGrid pages by the number of cells like PagingStrategyCells, and also splits very wide sheets into column blocks
+	PagingStrategyGrid = "grid"
+	// PagingStrategyTokens pages by the estimated tokens of the output (EXCEL_MCP_PAGING_TOKENS_LIMIT)
+	PagingStrategyTokens = "tokens"
+	// PagingStrategyTables pages by the number of cells like PagingStrategyCells without splitting a table across a page boundary
+	// more than needed, and repeats the header row of the table at the top of each page
+	PagingStrategyTables = "tables"
+)
+
+var pagingStrategies = []string{PagingStrategyCells, PagingStrategyGrid, PagingStrategyTokens, PagingStrategyTables}
+
+// newPagingStrategy returns the paging strategy of the worksheet selected by the configuration.
+// The options are the output options of the pages, which the tokens strategy estimates the size of.
+func newPagingStrategy(ctx context.Context, config EnvConfig, worksheet excel.Worksheet, options readSheetOptions) (excel.PagingStrategy, error) {
+	switch config.EXCEL_MCP_PAGING_STRATEGY {
+	case PagingStrategyGrid:
+		return excel.NewGridPagingStrategy(config.EXCEL_MCP_PAGING_CELLS_LIMIT, worksheet)
+	case PagingStrategyTokens:
+		return excel.NewTokenBudgetPagingStrategy(ctx, config.EXCEL_MCP_PAGING_TOKENS_LIMIT, tokenEstimateOf(options), worksheet)
+	case PagingStrategyTables:
+		return excel.NewTableAwarePagingStrategy(config.EXCEL_MCP_PAGING_CELLS_LIMIT, worksheet)
+	default:
+		return worksheet.GetPagingStrategy(config.EXCEL_MCP_PAGING_CELLS_LIMIT)
+	}
+}
+
+// tokenEstimateOf returns the rough size of a page rendered with the output options.
+func tokenEstimateOf(options readSheetOptions) excel.TokenEstimate {
+	var estimate excel.TokenEstimate
+	switch options.OutputFormat {
+	case OutputFormatMarkdown:
+		// "| value " and "| 1 |"
+		estimate = excel.TokenEstimate{CellOverhead: 1, RowOverhead: 2}
+	case OutputFormatCSV:
+		// "value," without row numbers
+		estimate = excel.TokenEstimate{CellOverhead: 1, RowOverhead: 1}
+	case OutputFormatJSON:
+		if options.JsonLayout == JsonLayoutRecords {
+			// "header":"value", with the header repeated in each record
+			estimate = excel.TokenEstimate{CellOverhead: 3, RowOverhead: 2}
+		} else {
+			// "value", and the row number
+			estimate = excel.TokenEstimate{CellOverhead: 1, RowOverhead: 2}
+		}
+		if options.ShowStyle {
+			// "s1", in the styles array
+			estimate.StyleOverhead = 2
+		}
+	default:
+		estimate = excel.DefaultTokenEstimate
+		if options.ShowStyle {
+			// style-ref="s1"
+			estimate.StyleOverhead = 4
+		}
+	}
+	estimate.Formula = options.ShowFormula
+	return estimate
+}
+
+// isColumnBlock returns true if the page is a block of columns of the grid paging strategy,
+// which does not cover all the columns of the used range.
+// A range given by the user is not a column block even if it is narrower than the used range.
+func isColumnBlock(strategy excel.PagingStrategy, pagingRanges []string, table *tableData, usedRange string) bool {
+	if _, ok := strategy.(*excel.GridPagingStrategy); !ok {
+		return false
+	}
+	usedStartCol, _, usedEndCol, _, err := excel.ParseRange(usedRange)
+	if err != nil {
+		return false
+	}
+	if table.startCol == usedStartCol && table.endCol == usedEndCol {
+		return false
+	}
+	return slices.ContainsFunc(pagingRanges, func(pagingRange string) bool {
+		startCol, startRow, endCol, endRow, err := excel.ParseRange(pagingRange)
+		return err == nil && startCol == table.startCol && startRow == table.startRow && endCol == table.endCol && endRow == table.endRow
+	})
+}
+
+// readHeaderContext reads the labels of a page from the header rows and the header column, 0 if there is none.
+// headerRows are the header rows of each column of the page, which differ between side-by-side tables.
+// The labels are read only if the page does not include the header row or the header column.
+// It returns nil if there are no labels to read.
+func readHeaderContext(worksheet excel.Worksheet, table *tableData, headerRows []int, headerColumn int) (*headerContext, error) {
+	// A column whose header row is on the page has no label to repeat
+	repeatedRows := make([]int, table.endCol-table.startCol+1)
+	var distinctRows []int
+	for j, row := range headerRows {
+		if j < len(repeatedRows) && row > 0 && table.startRow > row {
+			repeatedRows[j] = row
+			if !slices.Contains(distinctRows, row) {
+				distinctRows = append(distinctRows, row)
+			}
+		}
+	}
+	readsColumnLabels := len(distinctRows) > 0
+	readsRowLabels := headerColumn > 0 && table.startCol > headerColumn
+	if !readsColumnLabels && !readsRowLabels {
+		return nil, nil
+	}
+	headers := &headerContext{}
+	if readsColumnLabels {
+		if len(distinctRows) == 1 {
+			headers.headerRow = distinctRows[0]
+		} else {
+			headers.columnHeaderRows = repeatedRows
+		}
+		headers.columnLabels = make([]string, 0, len(repeatedRows))
+		for j, row := range repeatedRows {
+			value := ""
+			if row > 0 {
+				cell, _ := excelize.CoordinatesToCellName(table.startCol+j, row)
+				var err error
+				if value, err = worksheet.GetValue(cell); err != nil {
+					return nil, err
+				}
+			}
+			headers.columnLabels = append(headers.columnLabels, value)
+		}
+	}
+	if readsRowLabels {
+		name, err := excelize.ColumnNumberToName(headerColumn)
+		if err != nil {
+			return nil, err
+		}
+		headers.headerColumn = name
+		headers.rowLabels = make([]string, 0, table.endRow-table.startRow+1)
+		for row := table.startRow; row <= table.endRow; row++ {
+			cell, _ := excelize.CoordinatesToCellName(headerColumn, row)
+			value, err := worksheet.GetValue(cell)
+			if err != nil {
+				return nil, err
+			}
+			headers.rowLabels = append(headers.rowLabels, value)
+		}
+	}
+	return headers, nil
+}
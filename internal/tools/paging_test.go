@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"testing"
+
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestIsColumnBlock(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	worksheet, err := excel.NewExcelizeExcel(file).FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worksheet.Release()
+	if err := worksheet.SetValue("F10", "last"); err != nil {
+		t.Fatal(err)
+	}
+	// A row of 6 columns exceeds the page size, so the sheet is split into blocks of columns
+	grid, err := excel.NewGridPagingStrategy(4, worksheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells, err := worksheet.GetPagingStrategy(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		strategy excel.PagingStrategy
+		table    *tableData
+		want     bool
+	}{
+		{"grid page", grid, &tableData{startCol: 3, startRow: 5, endCol: 4, endRow: 6}, true},
+		{"range given by the user", grid, &tableData{startCol: 2, startRow: 5, endCol: 3, endRow: 6}, false},
+		{"all columns", grid, &tableData{startCol: 1, startRow: 1, endCol: 6, endRow: 5}, false},
+		{"narrow range of another strategy", cells, &tableData{startCol: 2, startRow: 5, endCol: 3, endRow: 10}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagingRanges := tt.strategy.CalculatePagingRanges()
+			if got := isColumnBlock(tt.strategy, pagingRanges, tt.table, "A1:F10"); got != tt.want {
+				t.Errorf("isColumnBlock() with pages %v = %v, want %v", pagingRanges, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressInterval is the minimum interval between progress notifications of a tool call
+const progressInterval = 500 * time.Millisecond
+
+type progressReporterKey struct{}
+
+// progressReporter sends notifications/progress for a tool call which has a progress token.
+type progressReporter struct {
+	server   *server.MCPServer
+	token    mcp.ProgressToken
+	mu       sync.Mutex
+	lastSent time.Time
+}
+
+// WithProgress wraps a tool handler function so that reportProgress can send
+// progress notifications when the client requested them with a progress token.
+func WithProgress(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mcpServer := server.ServerFromContext(ctx)
+		if request.Params.Meta != nil && request.Params.Meta.ProgressToken != nil && mcpServer != nil {
+			ctx = context.WithValue(ctx, progressReporterKey{}, &progressReporter{
+				server: mcpServer,
+				token:  request.Params.Meta.ProgressToken,
+			})
+		}
+		return handler(ctx, request)
+	}
+}
+
+// reportProgress notifies the client of the progress of the current tool call.
+// Notifications are throttled, except for the one that reports completion.
+func reportProgress(ctx context.Context, current int, total int, message string) {
+	reporter, ok := ctx.Value(progressReporterKey{}).(*progressReporter)
+	if !ok {
+		return
+	}
+	reporter.mu.Lock()
+	now := time.Now()
+	if current < total && now.Sub(reporter.lastSent) < progressInterval {
+		reporter.mu.Unlock()
+		return
+	}
+	reporter.lastSent = now
+	reporter.mu.Unlock()
+
+	params := map[string]any{
+		"progressToken": reporter.token,
+		"progress":      current,
+		"total":         total,
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	// Progress is best effort; a failure to notify must not fail the tool call
+	_ = reporter.server.SendNotificationToClient(ctx, string(mcp.MethodNotificationProgress), params)
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/xuri/excelize/v2"
+)
+
+// newTestImportFiles returns a workbook with a value in A1 and a JSON file of two records to import into it.
+func newTestImportFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.xlsx")
+	file := excelize.NewFile()
+	defer file.Close()
+	if err := file.SetCellValue("Sheet1", "A1", "before"); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "records.json")
+	if err := os.WriteFile(jsonPath, []byte(`[{"name":"a","value":1},{"name":"b","value":2}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, jsonPath
+}
+
+func TestCancelledCallLeavesWorkbookUntouched(t *testing.T) {
+	path, jsonPath := newTestImportFiles(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		call func() (*mcp.CallToolResult, error)
+	}{
+		{"import json", func() (*mcp.CallToolResult, error) {
+			return importJson(ctx, path, "Sheet1", jsonPath, "A1", true, false)
+		}},
+		{"find and replace", func() (*mcp.CallToolResult, error) {
+			return findReplace(ctx, path, "Sheet1", "before", "after", "", false, false)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.call(); !errors.Is(err, context.Canceled) {
+				t.Fatalf("err = %v, want %v", err, context.Canceled)
+			}
+			after, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(after, before) {
+				t.Error("expected the cancelled call not to write the workbook")
+			}
+		})
+	}
+}
+
+func TestCancelledExportLeavesOutputUntouched(t *testing.T) {
+	path, _ := newTestImportFiles(t)
+	outputPath := filepath.Join(filepath.Dir(path), "out.csv")
+	if err := os.WriteFile(outputPath, []byte("previous\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := exportCsv(ctx, path, "Sheet1", outputPath, "", ","); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	after, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != "previous\n" {
+		t.Errorf("output = %q, want the previous export", after)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected no temporary file to be left, got %d entries", len(entries))
+	}
+
+	if _, err := exportCsv(context.Background(), path, "Sheet1", outputPath, "", ","); err != nil {
+		t.Fatal(err)
+	}
+	after, err = os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != "before\n" {
+		t.Errorf("output = %q, want %q", after, "before\n")
+	}
+}
+
+func TestWithProgressNotifiesProgressToken(t *testing.T) {
+	path, jsonPath := newTestImportFiles(t)
+	mcpServer := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("excel_import_json"), WithProgress(handleImportJson))
+	session := newTestClientSession("session-1")
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	ctx := mcpServer.WithContext(context.Background(), session)
+
+	call := func(meta map[string]any) {
+		t.Helper()
+		params := map[string]any{
+			"name": "excel_import_json",
+			"arguments": map[string]any{
+				"fileAbsolutePath": path,
+				"sheetName":        "Sheet1",
+				"jsonPath":         jsonPath,
+			},
+		}
+		if meta != nil {
+			params["_meta"] = meta
+		}
+		message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response, isErr := mcpServer.HandleMessage(ctx, message).(mcp.JSONRPCError); isErr {
+			t.Fatalf("tools/call failed: %+v", response)
+		}
+	}
+
+	// Nothing is sent without a progress token
+	call(nil)
+	select {
+	case notification := <-session.notifications:
+		t.Fatalf("unexpected notification: %+v", notification)
+	default:
+	}
+
+	call(map[string]any{"progressToken": "import-1"})
+	var completed bool
+	for len(session.notifications) > 0 {
+		notification := <-session.notifications
+		if notification.Method != string(mcp.MethodNotificationProgress) {
+			t.Errorf("method = %s, want %s", notification.Method, mcp.MethodNotificationProgress)
+			continue
+		}
+		fields := notification.Params.AdditionalFields
+		if fields["progressToken"] != "import-1" {
+			t.Errorf("progressToken = %v, want %q", fields["progressToken"], "import-1")
+		}
+		if fields["progress"] == 2 && fields["total"] == 2 {
+			completed = true
+		}
+	}
+	if !completed {
+		t.Error("no progress notification reported the completion of the import")
+	}
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+type ExcelPromptArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+	SheetName        string `zog:"sheetName"`
+	Range            string `zog:"range"`
+}
+
+var excelPromptArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+	"sheetName":        z.String(),
+	"range":            z.String(),
+})
+
+type excelPrompt struct {
+	name        string
+	description string
+	withRange   bool
+	// tools that the instructions rely on. The prompt is hidden if any of them is not registered.
+	tools        []string
+	instructions func(args ExcelPromptArguments) string
+}
+
+var excelPrompts = []excelPrompt{
+	{
+		name:        "excel_summarize_workbook",
+		description: "Summarize the contents of an Excel workbook",
+		tools:       []string{"excel_describe_sheets", "excel_read_sheet"},
+		instructions: func(args ExcelPromptArguments) string {
+			return fmt.Sprintf(`Summarize the Excel workbook %s.
+
+1. Call excel_describe_sheets to list the sheets, their used ranges and tables.
+2. Call excel_read_sheet for %s. Follow the paging ranges and read only as many pages as needed to understand the data.
+3. Write a summary for a non-technical reader: the purpose of each sheet, the main tables and their columns, key figures such as totals, and anything that looks incomplete or inconsistent.
+
+Do not modify the workbook.`,
+				args.FileAbsolutePath, sheetTarget(args.SheetName, "each sheet"))
+		},
+	},
+	{
+		name:        "excel_clean_up_table",
+		description: "Clean up a table in an Excel sheet (whitespace, inconsistent values, formats)",
+		withRange:   true,
+		tools:       []string{"excel_describe_sheets", "excel_read_sheet", "excel_write_to_sheet", "excel_format_range"},
+		instructions: func(args ExcelPromptArguments) string {
+			return fmt.Sprintf(`Clean up the table %s in %s of the Excel workbook %s.
+
+1. Call excel_describe_sheets and excel_read_sheet to find the table and read it including the header row.
+2. Look for problems: leading or trailing whitespace, inconsistent capitalization or spelling of the same value, numbers or dates stored as text, inconsistent date formats and duplicated rows.
+3. Present the problems you found and the changes you plan to make, and ask for confirmation before writing anything.
+4. After confirmation, write the corrected values with excel_write_to_sheet and apply consistent number formats and a bold header with excel_format_range.
+5. Read the table again with excel_read_sheet and report what was changed.`,
+				rangeTarget(args.Range), sheetTarget(args.SheetName, "the sheet containing the main table"), args.FileAbsolutePath)
+		},
+	},
+	{
+		name:        "excel_audit_formulas",
+		description: "Review the formulas in an Excel workbook for errors and risky patterns",
+		tools:       []string{"excel_describe_sheets", "excel_read_sheet"},
+		instructions: func(args ExcelPromptArguments) string {
+			return fmt.Sprintf(`Audit the formulas in %s of the Excel workbook %s.
+
+1. Call excel_describe_sheets to list the sheets and their used ranges.
+2. Call excel_read_sheet with showFormula set to true to read the formulas, and again without it to see the calculated values.
+3. Report cells that show errors (#REF!, #DIV/0!, #N/A, #VALUE!, #NAME?), formulas that break the pattern of their neighbours in a row or column, hard-coded numbers inside formulas, references to empty cells or ranges that do not cover the whole data, and totals that do not include all rows.
+4. For each finding, give the cell address, the formula, the problem and a suggested fix.
+
+Do not modify the workbook.`,
+				sheetTarget(args.SheetName, "every sheet"), args.FileAbsolutePath)
+		},
+	},
+	{
+		name:        "excel_build_chart",
+		description: "Build a chart from a range in an Excel sheet",
+		withRange:   true,
+		tools:       []string{"excel_read_sheet", "excel_add_chart"},
+		instructions: func(args ExcelPromptArguments) string {
+			return fmt.Sprintf(`Build a chart from the data %s in %s of the Excel workbook %s.
+
+1. Call excel_read_sheet to read the data including its header row.
+2. Choose the chart type that fits the data: line for time series, bar or col for comparing categories, pie only for parts of a single whole. Explain your choice briefly.
+3. Call excel_add_chart with the data range, the chart type and a descriptive title. Place the chart next to the data so that it does not cover any values.
+4. Report the chart type, the data range and where the chart was placed.`,
+				rangeTarget(args.Range), sheetTarget(args.SheetName, "the sheet containing the data"), args.FileAbsolutePath)
+		},
+	},
+}
+
+// AddExcelPrompts registers prompts for common spreadsheet workflows.
+// It must be called after the tools are registered.
+func AddExcelPrompts(server *server.MCPServer) {
+	for _, p := range excelPrompts {
+		available := !slices.ContainsFunc(p.tools, func(name string) bool {
+			return server.GetTool(name) == nil
+		})
+		if !available {
+			continue
+		}
+		options := []mcp.PromptOption{
+			mcp.WithPromptDescription(p.description),
+			mcp.WithArgument("fileAbsolutePath",
+				mcp.RequiredArgument(),
+				mcp.ArgumentDescription("Absolute path to the Excel file"),
+			),
+			mcp.WithArgument("sheetName",
+				mcp.ArgumentDescription("Sheet name in the Excel file. If omitted, all sheets are considered"),
+			),
+		}
+		if p.withRange {
+			options = append(options, mcp.WithArgument("range",
+				mcp.ArgumentDescription("Range of cells in the Excel sheet (e.g., \"A1:C10\"). If omitted, the range is detected from the sheet"),
+			))
+		}
+		server.AddPrompt(mcp.NewPrompt(p.name, options...), handlePrompt(p))
+	}
+}
+
+func handlePrompt(p excelPrompt) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		arguments := make(map[string]any, len(request.Params.Arguments))
+		for k, v := range request.Params.Arguments {
+			arguments[k] = v
+		}
+		args := ExcelPromptArguments{}
+		if issues := excelPromptArgumentsSchema.Parse(arguments, &args); len(issues) != 0 {
+			return nil, imcp.NewZogIssueMapError(issues)
+		}
+		return mcp.NewGetPromptResult(
+			p.description,
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(p.instructions(args))),
+			},
+		), nil
+	}
+}
+
+func sheetTarget(sheetName string, fallback string) string {
+	if sheetName == "" {
+		return fallback
+	}
+	return fmt.Sprintf("the sheet [%s]", sheetName)
+}
+
+func rangeTarget(rangeStr string) string {
+	if rangeStr == "" {
+		return "(detect the range from the sheet)"
+	}
+	return fmt.Sprintf("in the range %s", rangeStr)
+}
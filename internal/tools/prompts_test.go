@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestPromptServer returns a server with the prompts of the registered tools.
+func newTestPromptServer(tools []string) *server.MCPServer {
+	mcpServer := server.NewMCPServer("test", "0.0.0", server.WithPromptCapabilities(false))
+	for _, tool := range tools {
+		mcpServer.AddTool(mcp.NewTool(tool), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, nil
+		})
+	}
+	AddExcelPrompts(mcpServer)
+	return mcpServer
+}
+
+// handlePromptMessage sends a JSON-RPC request to the server and returns the result of the response.
+// It returns the error of the response, if any.
+func handlePromptMessage(t *testing.T, mcpServer *server.MCPServer, method string, params map[string]any) (json.RawMessage, *mcp.JSONRPCError) {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch response := mcpServer.HandleMessage(context.Background(), message).(type) {
+	case mcp.JSONRPCResponse:
+		result, err := json.Marshal(response.Result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result, nil
+	case mcp.JSONRPCError:
+		return nil, &response
+	default:
+		t.Fatalf("unexpected response: %+v", response)
+		return nil, nil
+	}
+}
+
+// listTestPrompts returns the prompts listed by the server.
+func listTestPrompts(t *testing.T, mcpServer *server.MCPServer) []mcp.Prompt {
+	t.Helper()
+	result, rpcErr := handlePromptMessage(t, mcpServer, string(mcp.MethodPromptsList), map[string]any{})
+	if rpcErr != nil {
+		t.Fatalf("failed to list the prompts: %+v", rpcErr)
+	}
+	var list mcp.ListPromptsResult
+	if err := json.Unmarshal(result, &list); err != nil {
+		t.Fatal(err)
+	}
+	return list.Prompts
+}
+
+func TestExcelPrompts(t *testing.T) {
+	var tools []string
+	for _, p := range excelPrompts {
+		tools = append(tools, p.tools...)
+	}
+	mcpServer := newTestPromptServer(tools)
+
+	prompts := listTestPrompts(t, mcpServer)
+	if len(prompts) != len(excelPrompts) {
+		t.Fatalf("got %d prompts, want %d", len(prompts), len(excelPrompts))
+	}
+
+	for _, p := range excelPrompts {
+		t.Run(p.name, func(t *testing.T) {
+			i := slices.IndexFunc(prompts, func(prompt mcp.Prompt) bool { return prompt.Name == p.name })
+			if i < 0 {
+				t.Fatalf("prompt %s is not listed", p.name)
+			}
+			var arguments []string
+			for _, argument := range prompts[i].Arguments {
+				arguments = append(arguments, argument.Name)
+				if argument.Required != (argument.Name == "fileAbsolutePath") {
+					t.Errorf("required of argument %s = %v", argument.Name, argument.Required)
+				}
+			}
+			wantArguments := []string{"fileAbsolutePath", "sheetName"}
+			if p.withRange {
+				wantArguments = append(wantArguments, "range")
+			}
+			if !slices.Equal(arguments, wantArguments) {
+				t.Errorf("arguments = %v, want %v", arguments, wantArguments)
+			}
+
+			args := map[string]any{"fileAbsolutePath": "/path/to/book.xlsx", "sheetName": "Sales"}
+			if p.withRange {
+				args["range"] = "A1:C10"
+			}
+			raw, rpcErr := handlePromptMessage(t, mcpServer, string(mcp.MethodPromptsGet), map[string]any{"name": p.name, "arguments": args})
+			if rpcErr != nil {
+				t.Fatalf("failed to get the prompt: %+v", rpcErr)
+			}
+			result, err := mcp.ParseGetPromptResult(&raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Messages) != 1 || result.Messages[0].Role != mcp.RoleUser {
+				t.Fatalf("messages = %+v, want a message of the user", result.Messages)
+			}
+			content, ok := result.Messages[0].Content.(mcp.TextContent)
+			if !ok {
+				t.Fatalf("content = %T, want text", result.Messages[0].Content)
+			}
+			wants := []string{"/path/to/book.xlsx", "the sheet [Sales]"}
+			if p.withRange {
+				wants = append(wants, "in the range A1:C10")
+			}
+			wants = append(wants, p.tools...)
+			for _, want := range wants {
+				if !strings.Contains(content.Text, want) {
+					t.Errorf("prompt does not contain %q:\n%s", want, content.Text)
+				}
+			}
+
+			// The path is required and must be absolute
+			for _, args := range []map[string]any{{"sheetName": "Sales"}, {"fileAbsolutePath": "book.xlsx"}} {
+				if _, rpcErr := handlePromptMessage(t, mcpServer, string(mcp.MethodPromptsGet), map[string]any{"name": p.name, "arguments": args}); rpcErr == nil {
+					t.Errorf("prompts/get with %v should fail", args)
+				}
+			}
+		})
+	}
+}
+
+func TestExcelPromptsFollowTools(t *testing.T) {
+	mcpServer := newTestPromptServer([]string{"excel_describe_sheets", "excel_read_sheet"})
+
+	var names []string
+	for _, prompt := range listTestPrompts(t, mcpServer) {
+		names = append(names, prompt.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"excel_audit_formulas", "excel_summarize_workbook"}; !slices.Equal(names, want) {
+		t.Errorf("prompts = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolAccess classifies the effect of a tool on the files it handles.
+type ToolAccess int
+
+const (
+	// ToolAccessRead tools never modify any file.
+	ToolAccessRead ToolAccess = iota
+	// ToolAccessExport tools never modify workbooks but write to the output path given as argument.
+	ToolAccessExport
+	// ToolAccessWrite tools modify workbooks or control the Excel application.
+	ToolAccessWrite
+)
+
+func (a ToolAccess) String() string {
+	switch a {
+	case ToolAccessRead:
+		return "read"
+	case ToolAccessExport:
+		return "export"
+	default:
+		return "write"
+	}
+}
+
+// ToolRegistry receives the tools to be served with their access classification.
+type ToolRegistry interface {
+	AddTool(access ToolAccess, tool mcp.Tool, handler server.ToolHandlerFunc)
+}
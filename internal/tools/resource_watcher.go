@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+// ResourceWatcher polls the workbooks behind subscribed resources and sends
+// notifications/resources/updated to the subscribers when they change on disk.
+// Polling is used instead of file system events because Excel saves a workbook
+// by replacing the file, and events are unreliable on network file shares.
+// A save kept in the workbook cache without writing the file is also a change.
+type ResourceWatcher struct {
+	interval      time.Duration
+	mu            sync.Mutex
+	subscriptions map[string]*resourceSubscription // key: resource URI
+	stop          chan struct{}
+}
+
+type resourceSubscription struct {
+	fileAbsolutePath string
+	sessions         map[string]struct{}
+	state            fileState
+	// unsaved is the content saved in the workbook cache but not written to the file yet
+	unsaved []byte
+}
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func NewResourceWatcher(interval time.Duration) *ResourceWatcher {
+	return &ResourceWatcher{
+		interval:      interval,
+		subscriptions: make(map[string]*resourceSubscription),
+		stop:          make(chan struct{}),
+	}
+}
+
+// RegisterHooks tracks resources/subscribe and resources/unsubscribe requests per session.
+// A subscription to a URI which cannot be watched is rejected with an error.
+func (w *ResourceWatcher) RegisterHooks(hooks *server.Hooks) {
+	hooks.AddOnRequestInitialization(func(ctx context.Context, id any, message any) error {
+		return validateSubscribeRequest(message)
+	})
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			w.subscribe(session.SessionID(), message.Params.URI)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			w.unsubscribe(session.SessionID(), message.Params.URI)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		w.removeSession(session.SessionID())
+	})
+}
+
+// Start begins polling subscribed workbooks until Stop is called.
+func (w *ResourceWatcher) Start(mcpServer *server.MCPServer) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.poll(mcpServer)
+			}
+		}
+	}()
+}
+
+func (w *ResourceWatcher) Stop() {
+	close(w.stop)
+}
+
+// validateSubscribeRequest returns an error if the message is a resources/subscribe request
+// whose URI is not an Excel resource which can be watched.
+func validateSubscribeRequest(message any) error {
+	raw, ok := message.(json.RawMessage)
+	if !ok {
+		return nil
+	}
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &request); err != nil || request.Method != string(mcp.MethodResourcesSubscribe) {
+		return nil
+	}
+	_, err := parseSubscriptionURI(request.Params.URI)
+	return err
+}
+
+// parseSubscriptionURI parses the URI of a subscribed resource into the arguments of the resource.
+func parseSubscriptionURI(uri string) (ExcelResourceArguments, error) {
+	args := ExcelResourceArguments{}
+	parsed, err := parseExcelResourceURI(uri)
+	if err != nil {
+		return args, err
+	}
+	// Paths outside the allowed roots are never watched
+	if issues := excelResourceArgumentsSchema.Parse(parsed, &args); len(issues) != 0 {
+		return args, imcp.NewZogIssueMapError(issues)
+	}
+	return args, nil
+}
+
+func (w *ResourceWatcher) subscribe(sessionID string, uri string) {
+	args, err := parseSubscriptionURI(uri)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	subscription, ok := w.subscriptions[uri]
+	if !ok {
+		subscription = &resourceSubscription{
+			fileAbsolutePath: args.FileAbsolutePath,
+			sessions:         make(map[string]struct{}),
+			state:            statFile(args.FileAbsolutePath),
+		}
+		subscription.unsaved, _ = excel.UnsavedWorkbookContent(args.FileAbsolutePath)
+		w.subscriptions[uri] = subscription
+	}
+	subscription.sessions[sessionID] = struct{}{}
+}
+
+func (w *ResourceWatcher) unsubscribe(sessionID string, uri string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if subscription, ok := w.subscriptions[uri]; ok {
+		delete(subscription.sessions, sessionID)
+		if len(subscription.sessions) == 0 {
+			delete(w.subscriptions, uri)
+		}
+	}
+}
+
+func (w *ResourceWatcher) removeSession(sessionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for uri, subscription := range w.subscriptions {
+		delete(subscription.sessions, sessionID)
+		if len(subscription.sessions) == 0 {
+			delete(w.subscriptions, uri)
+		}
+	}
+}
+
+type resourceUpdate struct {
+	uri        string
+	sessionIDs []string
+}
+
+func (w *ResourceWatcher) poll(mcpServer *server.MCPServer) {
+	var updates []resourceUpdate
+	w.mu.Lock()
+	for uri, subscription := range w.subscriptions {
+		state := statFile(subscription.fileAbsolutePath)
+		unsaved, _ := excel.UnsavedWorkbookContent(subscription.fileAbsolutePath)
+		if state == subscription.state && sameContent(unsaved, subscription.unsaved) {
+			continue
+		}
+		subscription.state = state
+		subscription.unsaved = unsaved
+		update := resourceUpdate{uri: uri}
+		for sessionID := range subscription.sessions {
+			update.sessionIDs = append(update.sessionIDs, sessionID)
+		}
+		updates = append(updates, update)
+	}
+	w.mu.Unlock()
+
+	for _, update := range updates {
+		for _, sessionID := range update.sessionIDs {
+			// The session may have been closed in the meantime; nothing to do in that case
+			_ = mcpServer.SendNotificationToSpecificClient(
+				sessionID,
+				mcp.MethodNotificationResourceUpdated,
+				map[string]any{"uri": update.uri},
+			)
+		}
+	}
+}
+
+// sameContent reports whether both are the same content kept by the workbook cache.
+// Each save keeps a new content, so comparing the identity is enough.
+func sameContent(a []byte, b []byte) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+}
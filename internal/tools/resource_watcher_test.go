@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+)
+
+type testClientSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newTestClientSession(id string) *testClientSession {
+	return &testClientSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (s *testClientSession) Initialize()       {}
+func (s *testClientSession) Initialized() bool { return true }
+func (s *testClientSession) SessionID() string { return s.id }
+func (s *testClientSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// newTestWatchedServer returns a server with a registered session and a workbook file to watch.
+func newTestWatchedServer(t *testing.T) (*server.MCPServer, *testClientSession, string) {
+	t.Helper()
+	mcpServer := server.NewMCPServer("test", "0.0.0", server.WithResourceCapabilities(true, false))
+	session := newTestClientSession("session-1")
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	if err := os.WriteFile(path, []byte("before"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return mcpServer, session, path
+}
+
+func TestResourceWatcherPollNotifiesChange(t *testing.T) {
+	mcpServer, session, path := newTestWatchedServer(t)
+	uri := "excel://" + path
+	watcher := NewResourceWatcher(time.Hour)
+	watcher.subscribe(session.SessionID(), uri)
+
+	// Nothing is sent while the file is unchanged
+	watcher.poll(mcpServer)
+	select {
+	case notification := <-session.notifications:
+		t.Fatalf("unexpected notification: %+v", notification)
+	default:
+	}
+
+	if err := os.WriteFile(path, []byte("after the change"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher.poll(mcpServer)
+	select {
+	case notification := <-session.notifications:
+		if notification.Method != mcp.MethodNotificationResourceUpdated {
+			t.Errorf("method = %s, want %s", notification.Method, mcp.MethodNotificationResourceUpdated)
+		}
+		if got := notification.Params.AdditionalFields["uri"]; got != uri {
+			t.Errorf("uri = %v, want %s", got, uri)
+		}
+	default:
+		t.Fatal("no notification was sent for the changed workbook")
+	}
+}
+
+func TestResourceWatcherUnsubscribe(t *testing.T) {
+	mcpServer, session, path := newTestWatchedServer(t)
+	uri := "excel://" + path
+	watcher := NewResourceWatcher(time.Hour)
+	watcher.subscribe(session.SessionID(), uri)
+	watcher.unsubscribe(session.SessionID(), uri)
+	if len(watcher.subscriptions) != 0 {
+		t.Errorf("subscriptions = %v, want none", watcher.subscriptions)
+	}
+
+	if err := os.WriteFile(path, []byte("after the change"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher.poll(mcpServer)
+	select {
+	case notification := <-session.notifications:
+		t.Fatalf("unexpected notification after unsubscribe: %+v", notification)
+	default:
+	}
+}
+
+func TestResourceWatcherRejectsInvalidSubscription(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("EXCEL_MCP_ALLOWED_ROOTS", root)
+	watcher := NewResourceWatcher(time.Hour)
+	hooks := &server.Hooks{}
+	watcher.RegisterHooks(hooks)
+	mcpServer := server.NewMCPServer("test", "0.0.0", server.WithHooks(hooks), server.WithResourceCapabilities(true, false))
+
+	tests := []struct {
+		uri     string
+		wantErr bool
+	}{
+		{"excel://" + filepath.Join(root, "book.xlsx"), false},
+		{"excel:///outside/of/roots/book.xlsx", true},
+		{"excel:///no/workbook", true},
+	}
+	for _, tt := range tests {
+		message, err := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "resources/subscribe",
+			"params":  map[string]any{"uri": tt.uri},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		response := mcpServer.HandleMessage(context.Background(), message)
+		if _, isErr := response.(mcp.JSONRPCError); isErr != tt.wantErr {
+			t.Errorf("subscribe %s = %+v, want error: %v", tt.uri, response, tt.wantErr)
+		}
+	}
+}
+
+func TestResourceWatcherPollNotifiesSaveInWorkbookCache(t *testing.T) {
+	excel.EnableWorkbookCache(time.Hour, LockWorkbookForWriting)
+	t.Cleanup(func() { excel.CloseWorkbookCache() })
+	mcpServer, session, _ := newTestWatchedServer(t)
+	path, _ := newTestImportFiles(t)
+	uri := "excel://" + path
+	watcher := NewResourceWatcher(time.Hour)
+	watcher.subscribe(session.SessionID(), uri)
+
+	workbook, release, err := excel.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worksheet, err := workbook.FindSheet("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worksheet.SetValue("A1", "after"); err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.Save(); err != nil {
+		t.Fatal(err)
+	}
+	worksheet.Release()
+	release()
+	if _, ok := excel.UnsavedWorkbookContent(path); !ok {
+		t.Fatal("expected the save to be kept in the workbook cache")
+	}
+
+	watcher.poll(mcpServer)
+	select {
+	case notification := <-session.notifications:
+		if got := notification.Params.AdditionalFields["uri"]; got != uri {
+			t.Errorf("uri = %v, want %s", got, uri)
+		}
+	default:
+		t.Fatal("no notification was sent for the save kept in the workbook cache")
+	}
+
+	// Nothing changed since the last poll
+	watcher.poll(mcpServer)
+	select {
+	case notification := <-session.notifications:
+		t.Fatalf("unexpected notification: %+v", notification)
+	default:
+	}
+}
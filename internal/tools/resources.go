@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	z "github.com/Oudwins/zog"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	imcp "github.com/negokaz/excel-mcp-server/internal/mcp"
+)
+
+const excelResourceURIScheme = "excel://"
+
+var workbookExtensions = []string{".xlsx", ".xlsm", ".xltx", ".xltm"}
+
+type ExcelResourceArguments struct {
+	FileAbsolutePath string `zog:"fileAbsolutePath"`
+	SheetName        string `zog:"sheetName"`
+	Range            string `zog:"range"`
+}
+
+var excelResourceArgumentsSchema = z.Struct(z.Shape{
+	"fileAbsolutePath": z.String().Test(AbsolutePathTest()).Required(),
+	"sheetName":        z.String(),
+	"range":            z.String(),
+})
+
+// AddExcelResourceTemplates exposes workbooks and sheet ranges as MCP resources.
+// Each template is served only if the tool returning the same data is served, so that
+// disabling excel_describe_sheets or excel_read_sheet also hides the data from resources.
+// The tools must be added beforehand.
+func AddExcelResourceTemplates(server *server.MCPServer) {
+	describable := server.GetTool("excel_describe_sheets") != nil
+	readable := server.GetTool("excel_read_sheet") != nil
+	// Both templates share one handler since the URI patterns overlap when the path contains "/"
+	handler := handleExcelResource(describable, readable)
+	if describable {
+		server.AddResourceTemplate(mcp.NewResourceTemplate("excel://{+path}", "Excel workbook",
+			mcp.WithTemplateDescription("Sheet information of the Excel file in the same format as excel_describe_sheets. 'path' is the absolute path to the Excel file"),
+			mcp.WithTemplateMIMEType("application/json"),
+		), handler)
+	}
+	if readable {
+		server.AddResourceTemplate(mcp.NewResourceTemplate("excel://{+path}/{sheet}/{range}", "Excel sheet range",
+			mcp.WithTemplateDescription("Values of the range in the sheet in the same format as excel_read_sheet (e.g., excel:///path/to/book.xlsx/Sheet1/A1:C10)"),
+			mcp.WithTemplateMIMEType("text/html"),
+		), handler)
+	}
+}
+
+// handleExcelResource returns the handler of the resources which serves the workbook resource
+// if describable, and the sheet range resource if readable.
+func handleExcelResource(describable bool, readable bool) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readExcelResource(ctx, request.Params.URI, describable, readable)
+	}
+}
+
+func readExcelResource(ctx context.Context, uri string, describable bool, readable bool) ([]mcp.ResourceContents, error) {
+	args := ExcelResourceArguments{}
+	parsed, err := parseExcelResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if issues := excelResourceArgumentsSchema.Parse(parsed, &args); len(issues) != 0 {
+		return toResourceContents(uri, "", imcp.NewToolResultZogIssueMap(issues), nil)
+	}
+	// The URI of a sheet range also matches the workbook template, and vice versa
+	if args.SheetName == "" && !describable {
+		return nil, fmt.Errorf("workbook resources are not available since excel_describe_sheets is disabled: %s", uri)
+	}
+	if args.SheetName != "" && !readable {
+		return nil, fmt.Errorf("sheet range resources are not available since excel_read_sheet is disabled: %s", uri)
+	}
+	unlock, err := lockWorkbook(ctx, args.FileAbsolutePath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if args.SheetName == "" {
+		result, err := describeSheets(ctx, args.FileAbsolutePath)
+		return toResourceContents(uri, "application/json", result, err)
+	}
+	result, err := readSheet(ctx, args.FileAbsolutePath, args.SheetName, args.Range, readSheetOptions{OutputFormat: OutputFormatHTML})
+	return toResourceContents(uri, "text/html", result, err)
+}
+
+// parseExcelResourceURI parses "excel://{+path}" and "excel://{+path}/{sheet}/{range}".
+// The path may be either percent-encoded as a whole or written as is. In the latter case,
+// the end of the path is detected by the extension of the Excel file.
+func parseExcelResourceURI(uri string) (map[string]any, error) {
+	if !strings.HasPrefix(uri, excelResourceURIScheme) {
+		return nil, fmt.Errorf("unsupported resource URI: %s", uri)
+	}
+	segments := strings.Split(strings.TrimPrefix(uri, excelResourceURIScheme), "/")
+	for i := range segments {
+		path, err := url.PathUnescape(strings.Join(segments[:i+1], "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource URI: %s: %w", uri, err)
+		}
+		if !slices.Contains(workbookExtensions, strings.ToLower(filepath.Ext(path))) {
+			continue
+		}
+		rest := segments[i+1:]
+		switch len(rest) {
+		case 0:
+			return map[string]any{"fileAbsolutePath": path}, nil
+		case 2:
+			sheetName, err := url.PathUnescape(rest[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid sheet name in resource URI: %s: %w", uri, err)
+			}
+			rangeStr, err := url.PathUnescape(rest[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid range in resource URI: %s: %w", uri, err)
+			}
+			return map[string]any{"fileAbsolutePath": path, "sheetName": sheetName, "range": rangeStr}, nil
+		default:
+			return nil, fmt.Errorf("resource URI must be excel://{path} or excel://{path}/{sheet}/{range}: %s", uri)
+		}
+	}
+	return nil, fmt.Errorf("resource URI does not contain an Excel file path (%s): %s", strings.Join(workbookExtensions, ", "), uri)
+}
+
+// toResourceContents converts a tool result into resource contents.
+func toResourceContents(uri string, mimeType string, result *mcp.CallToolResult, err error) ([]mcp.ResourceContents, error) {
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, textContent.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if result.IsError {
+		return nil, errors.New(text)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: mimeType,
+			Text:     text,
+		},
+	}, nil
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExcelResourceURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name: "raw workbook path",
+			uri:  "excel:///home/user/book.xlsx",
+			want: map[string]any{"fileAbsolutePath": "/home/user/book.xlsx"},
+		},
+		{
+			name: "percent-encoded workbook path",
+			uri:  "excel://%2Fhome%2Fuser%2Fmy%20book.xlsm",
+			want: map[string]any{"fileAbsolutePath": "/home/user/my book.xlsm"},
+		},
+		{
+			name: "raw path with sheet and range",
+			uri:  "excel:///home/user/book.xlsx/Sheet%201/A1:C10",
+			want: map[string]any{"fileAbsolutePath": "/home/user/book.xlsx", "sheetName": "Sheet 1", "range": "A1:C10"},
+		},
+		{
+			name: "percent-encoded path with sheet and range",
+			uri:  "excel://C%3A%5CUsers%5Cbook.XLSX/Sheet1/B2",
+			want: map[string]any{"fileAbsolutePath": "C:\\Users\\book.XLSX", "sheetName": "Sheet1", "range": "B2"},
+		},
+		{
+			name:    "missing range",
+			uri:     "excel:///home/user/book.xlsx/Sheet1",
+			wantErr: true,
+		},
+		{
+			name:    "not an excel file",
+			uri:     "excel:///home/user/book.csv",
+			wantErr: true,
+		},
+		{
+			name:    "other scheme",
+			uri:     "file:///home/user/book.xlsx",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExcelResourceURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExcelResourceURI(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseExcelResourceURI(%q) = %v, want %v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
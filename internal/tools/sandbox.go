@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseAllowedRoots splits a list of root directories separated by os.PathListSeparator
+// (":" on Unix, ";" on Windows) in the same manner as the PATH environment variable.
+func parseAllowedRoots(value string) []string {
+	var roots []string
+	for _, root := range filepath.SplitList(value) {
+		root = strings.TrimSpace(root)
+		if root != "" {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// validatePathInAllowedRoots checks that the path stays inside one of the roots
+// after resolving symbolic links. An empty roots list disables the check.
+func validatePathInAllowedRoots(path string, roots []string) error {
+	if len(roots) == 0 {
+		return nil
+	}
+	if hasParentDirElement(path) {
+		return fmt.Errorf("path '%s' must not contain '..' elements", path)
+	}
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path '%s': %w", path, err)
+	}
+	for _, root := range roots {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		if isWithinRoot(resolved, resolvedRoot) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path '%s' is outside of the allowed root directories", path)
+}
+
+func hasParentDirElement(path string) bool {
+	elements := strings.FieldsFunc(path, func(r rune) bool {
+		return os.IsPathSeparator(uint8(r))
+	})
+	for _, element := range elements {
+		if element == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath resolves symbolic links in the path.
+// Since the path may point to a file which will be created (e.g. export output),
+// the longest existing ancestor is resolved and the rest of the path is joined to it.
+func resolvePath(path string) (string, error) {
+	cleaned := filepath.Clean(path)
+	resolved, err := filepath.EvalSymlinks(cleaned)
+	if err == nil {
+		return resolved, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	parent := filepath.Dir(cleaned)
+	if parent == cleaned {
+		return cleaned, nil
+	}
+	resolvedParent, err := resolvePath(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(cleaned)), nil
+}
+
+func isWithinRoot(path string, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
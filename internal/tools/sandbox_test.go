@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidatePathInAllowedRoots(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "project")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "book.xlsx"), []byte{}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	linkToOutside := filepath.Join(root, "link")
+	if err := os.Symlink(outside, linkToOutside); err != nil {
+		t.Skipf("symlink is not supported: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		roots   []string
+		wantErr bool
+	}{
+		{name: "no roots allows everything", path: filepath.Join(outside, "a.xlsx"), roots: nil},
+		{name: "existing file inside root", path: filepath.Join(root, "book.xlsx"), roots: []string{root}},
+		{name: "new file inside root", path: filepath.Join(root, "new", "out.csv"), roots: []string{root}},
+		{name: "root itself", path: root, roots: []string{root}},
+		{name: "file outside root", path: filepath.Join(outside, "a.xlsx"), roots: []string{root}, wantErr: true},
+		{name: "sibling with common prefix", path: root + "2" + string(filepath.Separator) + "a.xlsx", roots: []string{root}, wantErr: true},
+		{name: "parent dir escape", path: root + string(filepath.Separator) + ".." + string(filepath.Separator) + "outside" + string(filepath.Separator) + "a.xlsx", roots: []string{root}, wantErr: true},
+		{name: "symlink escape", path: filepath.Join(linkToOutside, "a.xlsx"), roots: []string{root}, wantErr: true},
+		{name: "second root matches", path: filepath.Join(outside, "a.xlsx"), roots: []string{root, outside}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePathInAllowedRoots(tt.path, tt.roots)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePathInAllowedRoots(%q, %v) error = %v, wantErr %v", tt.path, tt.roots, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAllowedRoots(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	got := parseAllowedRoots(" /a " + sep + sep + "/b")
+	want := []string{"/a", "/b"}
+	if len(got) != len(want) {
+		t.Fatalf("parseAllowedRoots() = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("parseAllowedRoots()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+)
+
+// maxSnapshotArgumentsLength is the maximum length of the tool arguments recorded in a snapshot
+const maxSnapshotArgumentsLength = 200
+
+// snapshotExemptTools do not take a snapshot since they operate on the history itself,
+// or only write the changes kept in the workbook cache
+var snapshotExemptTools = map[string]bool{
+	"excel_undo":                  true,
+	"excel_flush_cached_workbook": true,
+	"excel_close_cached_workbook": true,
+}
+
+// Snapshot is a copy of a workbook taken before a mutating tool call.
+type Snapshot struct {
+	ID string `json:"id"`
+	// Tool and Arguments identify the tool call which the snapshot was taken before
+	Tool      string    `json:"tool"`
+	Arguments string    `json:"arguments"`
+	CreatedAt time.Time `json:"createdAt"`
+	// FileExisted is false if the tool call created the workbook
+	FileExisted bool `json:"fileExisted"`
+}
+
+// snapshotStore keeps the snapshots of a workbook in its history directory.
+// Each snapshot consists of "<id>.json" with the metadata and "<id>.xlsx" (or the extension of the workbook) with the content.
+type snapshotStore struct {
+	path  string
+	dir   string
+	limit int
+}
+
+// WithSnapshot wraps a tool handler function so that a write tool takes a snapshot of the workbook
+// given by the fileAbsolutePath argument before it runs. The snapshot is discarded if the workbook
+// was not changed. Otherwise, the result reports the snapshot so that the call can be undone.
+// The handler must run while holding the workbook lock.
+func WithSnapshot(toolName string, access ToolAccess, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if access != ToolAccessWrite || snapshotExemptTools[toolName] {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		path, _ := request.GetArguments()["fileAbsolutePath"].(string)
+		if path == "" || !filepath.IsAbs(path) {
+			return handler(ctx, request)
+		}
+		if dryRun, _ := request.GetArguments()["dryRun"].(bool); dryRun {
+			// A dry run never writes the workbook
+			return handler(ctx, request)
+		}
+		config, issues := LoadConfig()
+		if issues != nil || config.EXCEL_MCP_SNAPSHOT_LIMIT == 0 {
+			return handler(ctx, request)
+		}
+		if validatePathInAllowedRoots(path, config.AllowedRoots()) != nil {
+			// The handler reports the error
+			return handler(ctx, request)
+		}
+		store := newSnapshotStore(path, config)
+		before := statFile(path)
+		unsavedBefore, _ := excel.UnsavedWorkbookContent(path)
+		snapshot, err := store.take(toolName, request.GetArguments())
+		if err != nil {
+			return nil, fmt.Errorf("failed to take a snapshot before %s: %w", toolName, err)
+		}
+
+		result, err := handler(ctx, request)
+
+		unsavedAfter, _ := excel.UnsavedWorkbookContent(path)
+		if statFile(path) == before && bytes.Equal(unsavedAfter, unsavedBefore) {
+			// Nothing changed (e.g. the call failed), so there is nothing to undo
+			store.remove(snapshot.ID)
+			return result, err
+		}
+		// Prune after the call so that excel_restore_snapshot can restore the oldest snapshot of a full history
+		restoredID, _ := request.GetArguments()["snapshotId"].(string)
+		if pruneErr := store.prune(restoredID); pruneErr != nil && err == nil {
+			err = fmt.Errorf("failed to prune snapshots after %s: %w", toolName, pruneErr)
+		}
+		if result != nil {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("Snapshot %s was taken before this %s call. Use excel_undo or excel_restore_snapshot to revert it.", snapshot.ID, toolName),
+			))
+			if output := writeOutputOf(result); output != nil {
+				output.SnapshotID = snapshot.ID
+			}
+		}
+		return result, err
+	}
+}
+
+func newSnapshotStore(path string, config EnvConfig) *snapshotStore {
+	path = filepath.Clean(path)
+	var dir string
+	if config.EXCEL_MCP_SNAPSHOT_DIR != "" {
+		// Distinguish workbooks with the same name in different directories
+		hash := sha1.Sum([]byte(path))
+		dir = filepath.Join(config.EXCEL_MCP_SNAPSHOT_DIR, filepath.Base(path)+"-"+hex.EncodeToString(hash[:])[:12])
+	} else {
+		dir = filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".history")
+	}
+	return &snapshotStore{path: path, dir: dir, limit: config.EXCEL_MCP_SNAPSHOT_LIMIT}
+}
+
+// take copies the current workbook into the history.
+// The changes saved in the workbook cache but not written to disk yet are part of the current workbook.
+func (s *snapshotStore) take(toolName string, arguments map[string]any) (*Snapshot, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	snapshot := &Snapshot{
+		ID:        now.UTC().Format("20060102T150405.000000000Z"),
+		Tool:      toolName,
+		Arguments: summarizeArguments(arguments),
+		CreatedAt: now,
+	}
+	if content, ok := excel.UnsavedWorkbookContent(s.path); ok {
+		snapshot.FileExisted = true
+		if err := os.WriteFile(s.contentPath(snapshot.ID), content, 0o644); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(s.path); err == nil {
+		snapshot.FileExisted = true
+		if err := excel.CopyFile(s.path, s.contentPath(snapshot.ID)); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	metadata, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(s.metadataPath(snapshot.ID), metadata, 0o644); err != nil {
+		s.remove(snapshot.ID)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// list returns the snapshots, newest first.
+func (s *snapshotStore) list() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []Snapshot{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			// Not a snapshot written by this server
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+func (s *snapshotStore) find(id string) (*Snapshot, error) {
+	snapshots, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.ID == id {
+			return &snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot not found: %s", id)
+}
+
+// restore replaces the workbook with the content of the snapshot.
+func (s *snapshotStore) restore(snapshot *Snapshot) error {
+	// The snapshot also replaces the changes which are not written from the workbook cache yet
+	excel.DiscardCachedWorkbook(s.path)
+	if !snapshot.FileExisted {
+		// The workbook did not exist before the tool call
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	content, err := os.Open(s.contentPath(snapshot.ID))
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return excel.WriteFileAtomically(s.path, func(w io.Writer) error {
+		_, err := io.Copy(w, content)
+		return err
+	})
+}
+
+func (s *snapshotStore) remove(id string) {
+	os.Remove(s.contentPath(id))
+	os.Remove(s.metadataPath(id))
+}
+
+// prune removes the oldest snapshots exceeding the limit.
+// The snapshot with the keep ID is neither removed nor counted towards the limit.
+func (s *snapshotStore) prune(keep string) error {
+	snapshots, err := s.list()
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, snapshot := range snapshots {
+		if snapshot.ID == keep {
+			continue
+		}
+		count++
+		if count > s.limit {
+			s.remove(snapshot.ID)
+		}
+	}
+	return nil
+}
+
+func (s *snapshotStore) contentPath(id string) string {
+	return filepath.Join(s.dir, id+filepath.Ext(s.path))
+}
+
+func (s *snapshotStore) metadataPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// summarizeArguments formats the tool arguments except the workbook path, truncated to a readable length.
+func summarizeArguments(arguments map[string]any) string {
+	summary := make(map[string]any, len(arguments))
+	for k, v := range arguments {
+		if k != "fileAbsolutePath" {
+			summary[k] = v
+		}
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return ""
+	}
+	text := string(data)
+	if len(text) > maxSnapshotArgumentsLength {
+		text = strings.ToValidUTF8(text[:maxSnapshotArgumentsLength], "") + "..."
+	}
+	return text
+}
@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	excel "github.com/negokaz/excel-mcp-server/internal/excel"
+)
+
+func newTestSnapshotStore(t *testing.T, limit int) (*snapshotStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	return newSnapshotStore(path, EnvConfig{EXCEL_MCP_SNAPSHOT_LIMIT: limit}), path
+}
+
+func TestSnapshotStoreTakeAndRestore(t *testing.T) {
+	store, path := newTestSnapshotStore(t, 10)
+	if err := os.WriteFile(path, []byte("before"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := store.take("excel_delete_rows", map[string]any{"fileAbsolutePath": path, "startRow": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Tool != "excel_delete_rows" || snapshot.Arguments != `{"startRow":3}` {
+		t.Errorf("unexpected snapshot metadata: %+v", snapshot)
+	}
+	if err := os.WriteFile(path, []byte("after"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "before" {
+		t.Errorf("content = %q, want %q", data, "before")
+	}
+}
+
+func TestSnapshotStoreRestoreRemovesCreatedWorkbook(t *testing.T) {
+	store, path := newTestSnapshotStore(t, 10)
+
+	snapshot, err := store.take("excel_write_to_sheet", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.FileExisted {
+		t.Error("expected FileExisted to be false")
+	}
+	if err := os.WriteFile(path, []byte("created"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the workbook to be removed, got %v", err)
+	}
+}
+
+func TestSnapshotStoreKeepsLimit(t *testing.T) {
+	store, path := newTestSnapshotStore(t, 2)
+	if err := os.WriteFile(path, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		snapshot, err := store.take("excel_write_to_sheet", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, snapshot.ID)
+	}
+	if err := store.prune(""); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := store.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("len(snapshots) = %d, want 2", len(snapshots))
+	}
+	if snapshots[0].ID != ids[2] || snapshots[1].ID != ids[1] {
+		t.Errorf("expected the newest snapshots first, got %s, %s", snapshots[0].ID, snapshots[1].ID)
+	}
+	if _, err := os.Stat(store.contentPath(ids[0])); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest snapshot content to be removed, got %v", err)
+	}
+}
+
+func TestSummarizeArgumentsTruncates(t *testing.T) {
+	summary := summarizeArguments(map[string]any{"values": strings.Repeat("x", 500)})
+	if len(summary) != maxSnapshotArgumentsLength+len("...") {
+		t.Errorf("len(summary) = %d", len(summary))
+	}
+}
+
+func TestWithSnapshot(t *testing.T) {
+	t.Setenv("EXCEL_MCP_SNAPSHOT_LIMIT", "10")
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	if err := os.WriteFile(path, []byte("before"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, _ := LoadConfig()
+	store := newSnapshotStore(path, config)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"fileAbsolutePath": path}
+
+	unchanged := WithSnapshot("excel_write_to_sheet", ToolAccessWrite, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("failed"), nil
+	})
+	if _, err := unchanged(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+	if snapshots, _ := store.list(); len(snapshots) != 0 {
+		t.Errorf("expected the snapshot of an unchanged workbook to be discarded, got %d", len(snapshots))
+	}
+
+	changed := WithSnapshot("excel_write_to_sheet", ToolAccessWrite, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), os.WriteFile(path, []byte("after!"), 0o644)
+	})
+	result, err := changed(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots, _ := store.list()
+	if len(snapshots) != 1 {
+		t.Fatalf("len(snapshots) = %d, want 1", len(snapshots))
+	}
+	notice := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+	if !strings.Contains(notice, snapshots[0].ID) || !strings.Contains(notice, "excel_write_to_sheet") {
+		t.Errorf("expected the result to report the snapshot, got %q", notice)
+	}
+}
+
+func TestWithSnapshotRestoresOldestOfFullHistory(t *testing.T) {
+	t.Setenv("EXCEL_MCP_SNAPSHOT_LIMIT", "2")
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	if err := os.WriteFile(path, []byte("v0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, _ := LoadConfig()
+	store := newSnapshotStore(path, config)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"fileAbsolutePath": path}
+
+	for _, content := range []string{"v1", "v2"} {
+		write := WithSnapshot("excel_write_to_sheet", ToolAccessWrite, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("done"), os.WriteFile(path, []byte(content), 0o644)
+		})
+		if _, err := write(context.Background(), request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, _ := store.list()
+	if len(snapshots) != 2 {
+		t.Fatalf("len(snapshots) = %d, want 2", len(snapshots))
+	}
+	oldest := snapshots[1]
+
+	restore := WithSnapshot("excel_restore_snapshot", ToolAccessWrite, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return restoreSnapshot(path, request.GetArguments()["snapshotId"].(string))
+	})
+	request.Params.Arguments = map[string]any{"fileAbsolutePath": path, "snapshotId": oldest.ID}
+	result, err := restore(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("failed to restore the oldest snapshot: %+v", result.Content)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "v0" {
+		t.Errorf("content = %q, want %q", content, "v0")
+	}
+	if _, err := store.find(oldest.ID); err != nil {
+		t.Errorf("expected the restored snapshot to be kept: %v", err)
+	}
+}
+
+func TestWithSnapshotOfWorkbookCache(t *testing.T) {
+	t.Setenv("EXCEL_MCP_SNAPSHOT_LIMIT", "10")
+	excel.EnableWorkbookCache(time.Hour, LockWorkbookForWriting)
+	t.Cleanup(func() { excel.CloseWorkbookCache() })
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	config, _ := LoadConfig()
+	store := newSnapshotStore(path, config)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"fileAbsolutePath": path}
+
+	write := func(value string) {
+		t.Helper()
+		handler := WithSnapshot("excel_write_to_sheet", ToolAccessWrite, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			workbook, release, err := excel.OpenFile(path)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			worksheet, err := workbook.FindSheet("Sheet1")
+			if err != nil {
+				return nil, err
+			}
+			defer worksheet.Release()
+			if err := worksheet.SetValue("A1", value); err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText("done"), workbook.Save()
+		})
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readA1 := func() string {
+		t.Helper()
+		workbook, release, err := excel.OpenFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+		worksheet, err := workbook.FindSheet("Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer worksheet.Release()
+		value, err := worksheet.GetValue("A1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	// Both calls are kept in the cache without writing the workbook
+	write("first")
+	write("second")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the workbook not to be written yet: %v", err)
+	}
+	snapshots, _ := store.list()
+	if len(snapshots) != 2 {
+		t.Fatalf("len(snapshots) = %d, want 2", len(snapshots))
+	}
+
+	// The snapshot of the second call has the unsaved content of the first call
+	if err := store.restore(&snapshots[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := readA1(); got != "first" {
+		t.Errorf("A1 = %q, want %q", got, "first")
+	}
+}
@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sparse modes of excel_read_sheet
+const (
+	// SparseModeOff renders all the cells of the range
+	SparseModeOff = "off"
+	// SparseModeCollapse omits the fully empty rows and columns and marks where they were
+	SparseModeCollapse = "collapse"
+	// SparseModeCells renders only the non-empty cells as pairs of the address and the value
+	SparseModeCells = "cells"
+)
+
+var sparseModes = []string{SparseModeOff, SparseModeCollapse, SparseModeCells}
+
+// emptyRun is a run of consecutive empty rows or columns which were collapsed.
+type emptyRun struct {
+	start int
+	end   int
+}
+
+// emptyRuns returns the runs of numbers between start and end which are not in numbers.
+// numbers must be sorted.
+func emptyRuns(numbers []int, start int, end int) []emptyRun {
+	var runs []emptyRun
+	next := start
+	for _, number := range numbers {
+		if number > next {
+			runs = append(runs, emptyRun{start: next, end: number - 1})
+		}
+		next = number + 1
+	}
+	if next <= end {
+		runs = append(runs, emptyRun{start: next, end: end})
+	}
+	return runs
+}
+
+func (r emptyRun) rowLabel() string {
+	if r.start == r.end {
+		return fmt.Sprintf("%d", r.start)
+	}
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+func (r emptyRun) columnLabel() string {
+	start, _ := excelize.ColumnNumberToName(r.start)
+	if r.start == r.end {
+		return start
+	}
+	end, _ := excelize.ColumnNumberToName(r.end)
+	return fmt.Sprintf("%s-%s", start, end)
+}
+
+// marker returns the text which takes the place of the run of empty rows in a table.
+func (r emptyRun) marker() string {
+	if r.start == r.end {
+		return fmt.Sprintf("row %d empty", r.start)
+	}
+	return fmt.Sprintf("rows %d-%d empty", r.start, r.end)
+}
+
+// collapseEmpty removes the rows and columns whose cells are all empty from the table.
+// The removed ones are rendered as markers.
+func (t *tableData) collapseEmpty() {
+	var rows, cols []int
+	// A merged cell is not empty, so that a merged area is not split by the markers
+	isEmpty := func(i int, j int) bool {
+		return t.values[i][j] == "" && t.mergedAreaAt(t.startCol+j, t.startRow+i) == nil
+	}
+	for i, values := range t.values {
+		for j := range values {
+			if !isEmpty(i, j) {
+				rows = append(rows, t.startRow+i)
+				break
+			}
+		}
+	}
+	for j := 0; j <= t.endCol-t.startCol; j++ {
+		for i := range t.values {
+			if !isEmpty(i, j) {
+				cols = append(cols, t.startCol+j)
+				break
+			}
+		}
+	}
+
+	values := make([][]string, 0, len(rows))
+	var styleRefs [][][]string
+	if t.styleRefs != nil {
+		styleRefs = make([][][]string, 0, len(rows))
+	}
+	var types [][]*cellTypeInfo
+	if t.types != nil {
+		types = make([][]*cellTypeInfo, 0, len(rows))
+	}
+	for _, row := range rows {
+		i := row - t.startRow
+		rowValues := make([]string, 0, len(cols))
+		var rowStyleRefs [][]string
+		var rowTypes []*cellTypeInfo
+		for _, col := range cols {
+			rowValues = append(rowValues, t.values[i][col-t.startCol])
+			if t.styleRefs != nil {
+				rowStyleRefs = append(rowStyleRefs, t.styleRefs[i][col-t.startCol])
+			}
+			if t.types != nil {
+				rowTypes = append(rowTypes, t.types[i][col-t.startCol])
+			}
+		}
+		values = append(values, rowValues)
+		if t.styleRefs != nil {
+			styleRefs = append(styleRefs, rowStyleRefs)
+		}
+		if t.types != nil {
+			types = append(types, rowTypes)
+		}
+	}
+	if t.headers != nil {
+		if t.headers.columnLabels != nil {
+			labels := make([]string, 0, len(cols))
+			for _, col := range cols {
+				labels = append(labels, t.headers.columnLabels[col-t.startCol])
+			}
+			t.headers.columnLabels = labels
+		}
+		if t.headers.columnHeaderRows != nil {
+			headerRows := make([]int, 0, len(cols))
+			for _, col := range cols {
+				headerRows = append(headerRows, t.headers.columnHeaderRows[col-t.startCol])
+			}
+			t.headers.columnHeaderRows = headerRows
+		}
+		if t.headers.rowLabels != nil {
+			labels := make([]string, 0, len(rows))
+			for _, row := range rows {
+				labels = append(labels, t.headers.rowLabels[row-t.startRow])
+			}
+			t.headers.rowLabels = labels
+		}
+	}
+	t.values = values
+	t.styleRefs = styleRefs
+	t.types = types
+	t.rowNumbers = append([]int{}, rows...)
+	t.colNumbers = append([]int{}, cols...)
+}
+
+// collapsed returns true if the empty rows and columns were removed from the table.
+func (t *tableData) collapsed() bool {
+	return t.rowNumbers != nil
+}
+
+// emptyRows returns the runs of the empty rows removed from the table.
+func (t *tableData) emptyRows() []emptyRun {
+	if !t.collapsed() {
+		return nil
+	}
+	return emptyRuns(t.rowNumbers, t.startRow, t.endRow)
+}
+
+// emptyColumns returns the runs of the empty columns removed from the table.
+func (t *tableData) emptyColumns() []emptyRun {
+	if !t.collapsed() {
+		return nil
+	}
+	return emptyRuns(t.colNumbers, t.startCol, t.endCol)
+}
+
+// tableLine is a row of the table or a run of empty rows which takes the place of the removed ones.
+type tableLine struct {
+	// index is the index of the row in values, or -1 for a run of empty rows
+	index    int
+	emptyRun emptyRun
+}
+
+// lines returns the rows of the table in order, along with the runs of the empty rows removed from the table.
+func (t *tableData) lines() []tableLine {
+	lines := make([]tableLine, 0, len(t.values))
+	if !t.collapsed() {
+		for i := range t.values {
+			lines = append(lines, tableLine{index: i})
+		}
+		return lines
+	}
+	runs := t.emptyRows()
+	for i, row := range t.rowNumbers {
+		for len(runs) > 0 && runs[0].start < row {
+			lines = append(lines, tableLine{index: -1, emptyRun: runs[0]})
+			runs = runs[1:]
+		}
+		lines = append(lines, tableLine{index: i})
+	}
+	for _, run := range runs {
+		lines = append(lines, tableLine{index: -1, emptyRun: run})
+	}
+	return lines
+}
+
+// sparseCell is a non-empty cell rendered as a pair of the address and the value.
+type sparseCell struct {
+	Cell      string `json:"cell"`
+	Value     string `json:"value"`
+	Style     string `json:"style,omitempty"`
+	styleRefs []string
+	*cellTypeInfo
+}
+
+// cells returns the non-empty cells of the table in the order of rows.
+// The repeated header row comes first if any.
+func (t *tableData) cells() []sparseCell {
+	cells := []sparseCell{}
+	columns := t.columnNumbers()
+	if t.hasHeaderRow() {
+		for j, label := range t.headers.columnLabels {
+			if label != "" {
+				cell, _ := excelize.CoordinatesToCellName(columns[j], t.headers.headerRowOf(j))
+				cells = append(cells, sparseCell{Cell: cell, Value: label})
+			}
+		}
+	}
+	for i, values := range t.values {
+		for j, value := range values {
+			if value == "" {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(columns[j], t.rowNumber(i))
+			c := sparseCell{Cell: cell, Value: value}
+			if t.styleRefs != nil {
+				c.styleRefs = t.styleRefs[i][j]
+				c.Style = strings.Join(c.styleRefs, " ")
+			}
+			if t.types != nil {
+				c.cellTypeInfo = t.types[i][j]
+			}
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+// renderCellListHTML renders the non-empty cells with the style definitions in HTML.
+func (t *tableData) renderCellListHTML() string {
+	var result strings.Builder
+	result.WriteString(t.styles.GenerateStyleDefinitions())
+	result.WriteString("<h2>Sheet Data</h2>\n")
+	result.WriteString("<table>\n<tr><th>cell</th><th>value</th></tr>\n")
+	for _, cell := range t.cells() {
+		attributes := ""
+		if len(cell.styleRefs) > 0 {
+			attributes += fmt.Sprintf(" style-ref=\"%s\"", cell.Style)
+		}
+		attributes += cell.cellTypeInfo.htmlAttributes()
+		tdTag := fmt.Sprintf("<td%s>", attributes)
+		result.WriteString(fmt.Sprintf("<tr><th>%s</th>%s%s</td></tr>\n", cell.Cell, tdTag, strings.ReplaceAll(html.EscapeString(cell.Value), "\n", "<br>")))
+	}
+	result.WriteString("</table>")
+	return result.String()
+}
+
+// renderCellListMarkdown renders the non-empty cells in Markdown.
+func (t *tableData) renderCellListMarkdown() string {
+	var result strings.Builder
+	result.WriteString("| cell | value |\n|---|---|\n")
+	for _, cell := range t.cells() {
+		result.WriteString(fmt.Sprintf("| %s | %s |\n", cell.Cell, escapeMarkdownTableCell(cell.Value)))
+	}
+	return result.String()
+}
+
+// renderCellListCSV renders the non-empty cells in CSV, a line of the address and the value for each cell.
+func (t *tableData) renderCellListCSV() (string, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	for _, cell := range t.cells() {
+		if err := writer.Write([]string{cell.Cell, cell.Value}); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestSparseTableData() *tableData {
+	return &tableData{
+		startCol: 1,
+		startRow: 1,
+		endCol:   4,
+		endRow:   6,
+		values: [][]string{
+			{"id", "", "name", ""},
+			{"", "", "", ""},
+			{"", "", "", ""},
+			{"1", "", "a", ""},
+			{"", "", "", ""},
+			{"2", "", "", ""},
+		},
+		styles: NewStyleRegistry(),
+	}
+}
+
+func TestEmptyRuns(t *testing.T) {
+	tests := []struct {
+		numbers []int
+		start   int
+		end     int
+		want    []emptyRun
+	}{
+		{[]int{1, 2, 3}, 1, 3, nil},
+		{[]int{2, 5}, 1, 7, []emptyRun{{1, 1}, {3, 4}, {6, 7}}},
+		{[]int{}, 3, 5, []emptyRun{{3, 5}}},
+	}
+	for _, tt := range tests {
+		if got := emptyRuns(tt.numbers, tt.start, tt.end); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("emptyRuns(%v, %d, %d) = %v, want %v", tt.numbers, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestTableDataCollapseEmpty(t *testing.T) {
+	table := newTestSparseTableData()
+	table.collapseEmpty()
+
+	want := "|   | A | C |\n" +
+		"|---|---|---|\n" +
+		"| 1 | id | name |\n" +
+		"|   | rows 2-3 empty |  |\n" +
+		"| 4 | 1 | a |\n" +
+		"|   | row 5 empty |  |\n" +
+		"| 6 | 2 |  |\n"
+	if got := table.renderMarkdown(); got != want {
+		t.Errorf("renderMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+	if got := table.emptyColumns(); !reflect.DeepEqual(got, []emptyRun{{2, 2}, {4, 4}}) {
+		t.Errorf("emptyColumns() = %v", got)
+	}
+}
+
+func TestTableDataCells(t *testing.T) {
+	table := newTestSparseTableData()
+	table.cellList = true
+
+	got, err := table.renderCSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "A1,id\nC1,name\nA4,1\nC4,a\nA6,2\n"
+	if got != want {
+		t.Errorf("renderCSV() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WriteOutput is embedded in the structured content of the tools which modify a workbook.
+// Its fields are filled in by the wrappers around the handlers: WithSnapshot and runDryRun.
+type WriteOutput struct {
+	SnapshotID string        `json:"snapshotId,omitempty" jsonschema:"ID of the snapshot taken before the call, to be passed to excel_restore_snapshot"`
+	DryRun     bool          `json:"dryRun,omitempty" jsonschema:"true if the call ran against a copy and nothing was written"`
+	Changes    *WorkbookDiff `json:"changes,omitempty" jsonschema:"Changes of the cells the call would make, only in a dry run"`
+}
+
+func (o *WriteOutput) writeOutput() *WriteOutput {
+	return o
+}
+
+type writeOutputHolder interface {
+	writeOutput() *WriteOutput
+}
+
+// writeOutputOf returns the WriteOutput embedded in the structured content of the result,
+// or nil if the result has none.
+func writeOutputOf(result *mcp.CallToolResult) *WriteOutput {
+	if result == nil {
+		return nil
+	}
+	if holder, ok := result.StructuredContent.(writeOutputHolder); ok {
+		return holder.writeOutput()
+	}
+	return nil
+}
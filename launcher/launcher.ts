@@ -23,6 +23,11 @@ function getBinaryPath(): string {
   }
 }
 
-childProcess.execFileSync(getBinaryPath(), process.argv, {
-  stdio: 'inherit',
-});
+// Pass only the user arguments (e.g. "call excel_read_sheet --args ...") and propagate the exit status
+try {
+  childProcess.execFileSync(getBinaryPath(), process.argv.slice(2), {
+    stdio: 'inherit',
+  });
+} catch (e: any) {
+  process.exit(typeof e.status === 'number' ? e.status : 1);
+}